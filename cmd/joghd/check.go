@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/raha-io/joghd/internal/checker"
+	"github.com/raha-io/joghd/internal/config"
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/report"
+)
+
+// headerFlags collects repeated -H "Name: value" flags.
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+	pairs := make([]string, 0, len(h))
+	for k, v := range h {
+		pairs = append(pairs, k+": "+v)
+	}
+	return strings.Join(pairs, ", ")
+}
+
+func (h headerFlags) Set(value string) error {
+	name, val, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header must be in 'Name: value' form, got %q", value)
+	}
+	h[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	return nil
+}
+
+// runCheck implements the check subcommand: it runs the checker once against a
+// single URL without a config file.
+func runCheck(args []string) int {
+	cfg := config.Default()
+
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	method := fs.String("method", "GET", "HTTP method")
+	expected := fs.Int("status", 200, "Expected HTTP status code")
+	timeout := fs.Duration("timeout", cfg.HTTP.Timeout, "Request timeout")
+	attempts := fs.Int("attempts", cfg.Retry.MaxAttempts, "Maximum attempts before declaring failure")
+	initialWait := fs.Duration("initial-wait", cfg.Retry.InitialWait, "Initial wait between retries")
+	maxWait := fs.Duration("max-wait", cfg.Retry.MaxWait, "Maximum wait between retries")
+	multiplier := fs.Float64("multiplier", cfg.Retry.Multiplier, "Multiplier for exponential backoff")
+	data := fs.String("data", "", "Request body; prefix with @ to read it from a file")
+	insecure := fs.Bool("insecure", false, "Skip TLS certificate verification")
+	caFile := fs.String("cacert", "", "PEM CA bundle to verify the server against")
+	certFile := fs.String("cert", "", "PEM client certificate for mutual TLS")
+	keyFile := fs.String("key", "", "PEM client key for mutual TLS")
+	follow := fs.Bool("follow-redirects", true, "Follow redirects")
+	maxRedirects := fs.Int("max-redirects", domain.DefaultMaxRedirects, "Maximum redirects to follow")
+	finalURL := fs.String("final-url", "", "Expected URL after redirects (a bare path matches the path only)")
+	proxy := fs.String("proxy", "", "Proxy URL (http, https, socks5) or 'direct'; defaults to HTTP_PROXY and friends")
+	output := fs.String("output", "text", "Output format: text or json")
+	alert := fs.Bool("alert", false, "Send a failure alert through the alerters configured in -config if the check fails")
+	configPath := fs.String("config", "config.toml", "Path to configuration file (used with -alert)")
+	headers := headerFlags{}
+	fs.Var(headers, "H", "Request header as 'Name: value' (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: joghd check [flags] <url>\n\nRun a single health check against a URL.\n\n")
+		fs.PrintDefaults()
+	}
+
+	// Accept the URL before or after the flags
+	var url string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		url, args = args[0], args[1:]
+	}
+	_ = fs.Parse(args)
+	if url == "" {
+		url = fs.Arg(0)
+	}
+	if url == "" {
+		fs.Usage()
+		return 2
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "error: unknown output format %q\n", *output)
+		return 2
+	}
+	if *attempts < 1 {
+		fmt.Fprintf(os.Stderr, "error: -attempts must be at least 1, got %d\n", *attempts)
+		return 2
+	}
+
+	if *alert {
+		loaded, err := config.Load(*configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: loading config: %v\n", err)
+			return 2
+		}
+		cfg = *loaded
+	}
+	httpCfg := cfg.HTTP
+	httpCfg.Timeout = *timeout
+	httpCfg.SkipTLSVerification = httpCfg.SkipTLSVerification || *insecure
+
+	target := domain.Target{
+		Name:           url,
+		URL:            url,
+		Method:         strings.ToUpper(*method),
+		ExpectedStatus: *expected,
+		Timeout:        *timeout,
+		Headers:        headers,
+		TLS: domain.TLS{
+			CAFile:   *caFile,
+			CertFile: *certFile,
+			KeyFile:  *keyFile,
+		}.Merge(httpCfg.TLS),
+		Proxy: domain.Proxy{URL: *proxy}.Merge(httpCfg.Proxy),
+
+		FollowRedirects:  follow,
+		MaxRedirects:     maxRedirects,
+		ExpectedFinalURL: *finalURL,
+	}
+	if path, ok := strings.CutPrefix(*data, "@"); ok {
+		target.BodyFile = path
+	} else {
+		target.Body = *data
+	}
+
+	chk := checker.New(
+		checker.WithHTTPClient(checker.NewRestyClient(httpCfg)),
+		checker.WithRetryConfig(config.RetryConfig{
+			MaxAttempts: *attempts,
+			InitialWait: *initialWait,
+			MaxWait:     *maxWait,
+			Multiplier:  *multiplier,
+		}),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	result := chk.Check(ctx, target)
+
+	if *output == "json" {
+		if err := writeCheckJSON(os.Stdout, result); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 2
+		}
+	} else {
+		writeCheckText(os.Stdout, result)
+	}
+
+	// A passing check sends nothing: a recovery alert for a target that
+	// never failed would read like a real recovery in the alert channel
+	if *alert && result.Success {
+		fmt.Fprintln(os.Stderr, "Check passed, no alert sent")
+	}
+	if *alert && !result.Success {
+		a := domain.NewFailureAlert(result)
+		alt := buildAlerter(&cfg)
+		if err := alt.Send(ctx, a); err != nil {
+			fmt.Fprintf(os.Stderr, "error: sending alert via %s: %v\n", alt.Name(), err)
+			return 2
+		}
+		fmt.Fprintf(os.Stderr, "Sent %s alert via %s\n", a.Type, alt.Name())
+	}
+
+	if !result.Success {
+		return 1
+	}
+	return 0
+}
+
+func writeCheckText(w io.Writer, result domain.CheckResult) {
+	status := "OK"
+	if !result.Success {
+		status = "FAIL"
+	}
+
+	fmt.Fprintf(w, "%s %s %s\n", status, result.Target.Method, result.Target.URL)
+	fmt.Fprintf(w, "  Status:    %d (expected %d)\n", result.ActualStatus, result.Target.ExpectedStatus)
+	fmt.Fprintf(w, "  Latency:   %s\n", result.Latency.Round(time.Microsecond))
+	if t := result.Timing; !t.IsZero() {
+		fmt.Fprintf(w, "    DNS lookup:       %s\n", t.DNSLookup.Round(time.Microsecond))
+		fmt.Fprintf(w, "    TCP connect:      %s\n", t.Connect.Round(time.Microsecond))
+		fmt.Fprintf(w, "    TLS handshake:    %s\n", t.TLSHandshake.Round(time.Microsecond))
+		fmt.Fprintf(w, "    Time to 1st byte: %s\n", t.TimeToFirstByte.Round(time.Microsecond))
+		fmt.Fprintf(w, "    Content transfer: %s\n", t.ContentTransfer.Round(time.Microsecond))
+		fmt.Fprintf(w, "    Conn reused:      %t\n", t.ConnReused)
+	}
+	if result.Proxy != "" {
+		fmt.Fprintf(w, "  Proxy:     %s\n", result.Proxy)
+	}
+	for _, r := range result.Redirects {
+		fmt.Fprintf(w, "  Redirect:  %d %s\n", r.StatusCode, r.URL)
+	}
+	if len(result.Redirects) > 0 {
+		fmt.Fprintf(w, "  Final URL: %s\n", result.FinalURL)
+	}
+	fmt.Fprintf(w, "  Attempts:  %d\n", result.Attempts)
+	fmt.Fprintf(w, "  Time:      %s\n", result.Timestamp.Format(time.RFC3339))
+	if result.Error != nil {
+		fmt.Fprintf(w, "  Error:     %v\n", result.Error)
+	}
+}
+
+func writeCheckJSON(w io.Writer, result domain.CheckResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report.NewResult(result))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCheckRejectsAttemptsBelowOne(t *testing.T) {
+	for _, attempts := range []string{"0", "-1"} {
+		if code := runCheck([]string{"http://127.0.0.1:1/", "-attempts", attempts}); code != 2 {
+			t.Errorf("-attempts %s: exit code %d, want 2", attempts, code)
+		}
+	}
+}
+
+func TestCheckAlertsOnlyOnFailure(t *testing.T) {
+	var sent atomic.Int32
+	tg := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer tg.Close()
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer target.Close()
+
+	cfg := filepath.Join(t.TempDir(), "config.toml")
+	toml := `
+[alerters.telegram]
+enabled = true
+bot_token = "123:abc"
+chat_id = "100"
+api_url = "` + tg.URL + `"
+`
+	if err := os.WriteFile(cfg, []byte(toml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := runCheck([]string{target.URL + "/up", "-alert", "-config", cfg, "-attempts", "1"}); code != 0 {
+		t.Fatalf("passing check: exit code %d, want 0", code)
+	}
+	if n := sent.Load(); n != 0 {
+		t.Errorf("passing check sent %d alert request(s), want none", n)
+	}
+
+	if code := runCheck([]string{target.URL + "/down", "-alert", "-config", cfg, "-attempts", "1"}); code != 1 {
+		t.Fatalf("failing check: exit code %d, want 1", code)
+	}
+	if n := sent.Load(); n == 0 {
+		t.Error("failing check sent no alert")
+	}
+}
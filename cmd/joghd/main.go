@@ -2,18 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/raha-io/joghd/internal/alerter"
+	"github.com/raha-io/joghd/internal/api"
+	"github.com/raha-io/joghd/internal/bot"
 	"github.com/raha-io/joghd/internal/checker"
 	"github.com/raha-io/joghd/internal/config"
 	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/incident"
+	"github.com/raha-io/joghd/internal/report"
 	"github.com/raha-io/joghd/internal/scheduler"
+	"github.com/raha-io/joghd/internal/silence"
 )
 
 var (
@@ -23,9 +33,21 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate":
+			os.Exit(runValidate(os.Args[2:]))
+		case "check":
+			os.Exit(runCheck(os.Args[2:]))
+		}
+	}
+
 	configPath := flag.String("config", "config.toml", "Path to configuration file")
 	mode := flag.String("mode", "", "Run mode: oneshot or continuous (overrides config)")
+	output := flag.String("output", "", "Oneshot report format: json, junit, tap or markdown")
+	outputFile := flag.String("output-file", "", "Write the oneshot report to this file instead of stdout")
 	showVersion := flag.Bool("version", false, "Show version information")
+	flag.Usage = usage
 	flag.Parse()
 
 	if *showVersion {
@@ -33,19 +55,32 @@ func main() {
 		os.Exit(0)
 	}
 
-	cfg, err := config.Load(*configPath)
-	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+	if *outputFile != "" && *output == "" {
+		log.Fatal("-output-file needs -output to choose the report format")
 	}
+	var format report.Format
+	if *output != "" {
+		f, err := report.ParseFormat(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		format = f
+	}
+
+	// Command-line mode flag overrides config, also on reload
+	overrides := []config.Override{config.WithMode(*mode)}
 
-	// Command-line mode flag overrides config
-	if *mode != "" {
-		cfg.App.Mode = *mode
+	cfg, err := config.Load(*configPath, overrides...)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
 	}
 
 	if len(cfg.Targets) == 0 {
 		log.Fatal("No targets configured")
 	}
+	if (*output != "" || *outputFile != "") && cfg.App.Mode != "oneshot" {
+		log.Fatalf("-output and -output-file only apply in oneshot mode, not %s", cfg.App.Mode)
+	}
 
 	log.Printf("Joghd starting in %s mode with %d targets", cfg.App.Mode, len(cfg.Targets))
 
@@ -59,8 +94,9 @@ func main() {
 		checker.WithConcurrency(cfg.App.Concurrency),
 	)
 
-	// Create alerter
-	alt := buildAlerter(cfg)
+	// Create alerter, muted for targets in maintenance
+	silences := silence.New(buildAlerter(cfg))
+	silences.Configure(cfg.Maintenance)
 
 	// Create context with signal handling
 	ctx, cancel := context.WithCancel(context.Background())
@@ -78,15 +114,25 @@ func main() {
 	// Run based on mode
 	switch cfg.App.Mode {
 	case "oneshot":
-		exitCode := runOneshot(ctx, chk, alt, cfg.Targets)
+		exitCode := runOneshot(ctx, chk, silences, cfg.Targets, format, *outputFile)
 		os.Exit(exitCode)
 	case "continuous":
-		runContinuous(ctx, chk, alt, cfg.Targets)
+		runContinuous(ctx, chk, httpClient, silences, cfg, *configPath, overrides)
 	default:
 		log.Fatalf("Unknown mode: %s", cfg.App.Mode)
 	}
 }
 
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: joghd [flags]\n       joghd <command> [flags]\n\n")
+	fmt.Fprintf(out, "Commands:\n")
+	fmt.Fprintf(out, "  check       Run a single health check against a URL\n")
+	fmt.Fprintf(out, "  validate    Validate the configuration and print the effective config\n\n")
+	fmt.Fprintf(out, "Flags:\n")
+	flag.PrintDefaults()
+}
+
 func buildAlerter(cfg *config.Config) alerter.Alerter {
 	composite := alerter.NewCompositeAlerter()
 
@@ -99,27 +145,80 @@ func buildAlerter(cfg *config.Config) alerter.Alerter {
 	return composite
 }
 
-func runOneshot(ctx context.Context, chk checker.Checker, alt alerter.Alerter, targets []domain.Target) int {
+func runOneshot(ctx context.Context, chk checker.Checker, alt alerter.Alerter, targets []domain.Target, format report.Format, outputFile string) int {
 	log.Println("Running oneshot health check...")
 
+	// Push targets are pinged by their jobs, there is nothing to check
+	polled := make([]domain.Target, 0, len(targets))
+	for _, t := range targets {
+		if t.Push() {
+			log.Printf("Skipping push target %s in oneshot mode", t.Name)
+			continue
+		}
+		polled = append(polled, t)
+	}
+	targets = polled
+
+	started := time.Now()
 	results := chk.CheckAll(ctx, targets)
+	duration := time.Since(started)
+
+	// A failing target whose dependency also failed is reported in the
+	// dependency's alert instead of its own
+	deps := domain.NewDependencyGraph(targets)
+	failed := make(map[string]bool)
+	for _, result := range results {
+		if !result.Success {
+			failed[result.Target.Name] = true
+		}
+	}
+	blocker := func(name string) string {
+		for _, dep := range deps.Ancestors(name) {
+			if failed[dep] {
+				return dep
+			}
+		}
+		return ""
+	}
 
 	hasFailures := false
 	for _, result := range results {
 		if result.Success {
-			log.Printf("[OK] %s: status=%d, latency=%s",
-				result.Target.Name, result.ActualStatus, result.Latency)
-		} else {
-			hasFailures = true
-			log.Printf("[FAIL] %s: status=%d, expected=%d, error=%v",
-				result.Target.Name, result.ActualStatus, result.Target.ExpectedStatus, result.Error)
-
-			// Send failure alert
-			alert := domain.NewFailureAlert(result)
-			if err := alt.Send(ctx, alert); err != nil {
-				log.Printf("Failed to send alert: %v", err)
+			log.Printf("[OK] %s: status=%d, latency=%s%s",
+				result.Target.Name, result.ActualStatus, result.Latency, proxySuffix(result))
+			continue
+		}
+
+		hasFailures = true
+		log.Printf("[FAIL] %s: status=%d, expected=%d%s, error=%v",
+			result.Target.Name, result.ActualStatus, result.Target.ExpectedStatus, proxySuffix(result), result.Error)
+
+		if dep := blocker(result.Target.Name); dep != "" {
+			log.Printf("Suppressed failure alert for %s: depends on %s, which is down", result.Target.Name, dep)
+			continue
+		}
+
+		// Send failure alert
+		alert := domain.NewFailureAlert(result)
+		for _, child := range deps.Dependents(result.Target.Name) {
+			if failed[child] && blocker(child) == result.Target.Name {
+				alert.Suppressed = append(alert.Suppressed, child)
 			}
 		}
+		switch err := alt.Send(ctx, alert); {
+		case errors.Is(err, alerter.ErrSilenced):
+			log.Printf("Did not send alert for %s: %v", result.Target.Name, err)
+		case err != nil:
+			log.Printf("Failed to send alert: %v", err)
+		}
+	}
+
+	if format != "" {
+		r := report.Report{Started: started, Duration: duration, Results: results}
+		if err := writeReport(format, outputFile, r); err != nil {
+			log.Printf("Failed to write %s report: %v", format, err)
+			return 1
+		}
 	}
 
 	if hasFailures {
@@ -131,11 +230,145 @@ func runOneshot(ctx context.Context, chk checker.Checker, alt alerter.Alerter, t
 	return 0
 }
 
-func runContinuous(ctx context.Context, chk checker.Checker, alt alerter.Alerter, targets []domain.Target) {
+// writeReport writes the oneshot report to path, or to stdout if path is empty.
+func writeReport(format report.Format, path string, r report.Report) error {
+	if path == "" {
+		return report.Write(os.Stdout, format, r)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := report.Write(f, format, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runContinuous(ctx context.Context, chk checker.Checker, httpClient *checker.RestyClient, silences *silence.Registry, cfg *config.Config, configPath string, overrides []config.Override) {
 	log.Println("Starting continuous monitoring...")
 
-	sched := scheduler.New(chk, alt, targets)
+	incidents := incident.New(
+		incident.WithStateFile(cfg.Incidents.StateFile),
+		incident.WithRetention(cfg.Incidents.Retention),
+	)
+	if err := incidents.Load(); err != nil {
+		log.Fatalf("Failed to load incidents: %v", err)
+	}
+
+	sched := scheduler.New(chk, silences, cfg.Targets,
+		scheduler.WithConcurrency(cfg.App.Concurrency),
+		scheduler.WithStartSpread(cfg.App.StartSpread),
+		scheduler.WithJitter(cfg.App.Jitter),
+		scheduler.WithIncidents(incidents),
+	)
+
+	var mu sync.Mutex
+	current := cfg
+	reload := func(reason string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		log.Printf("Reloading config (%s)...", reason)
+
+		next, err := config.Load(configPath, overrides...)
+		if err != nil {
+			log.Printf("Config reload rejected, keeping previous config: %v", err)
+			return
+		}
+		if len(next.Targets) == 0 {
+			log.Println("Config reload rejected, keeping previous config: no targets configured")
+			return
+		}
+		if changed := restartSections(current, next); len(changed) > 0 {
+			log.Printf("Config reload rejected, keeping previous config: changes to %s require a restart",
+				strings.Join(changed, ", "))
+			return
+		}
+
+		if err := sched.Reload(next.Targets); err != nil {
+			log.Printf("Config reload rejected, keeping previous config: %v", err)
+			return
+		}
+		silences.Configure(next.Maintenance)
+		httpClient.Retain(next.Targets)
+		current = next
+	}
+
+	// Reload targets on SIGHUP
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	defer signal.Stop(hupCh)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hupCh:
+				reload("SIGHUP")
+			}
+		}
+	}()
+
+	if cfg.App.WatchConfig {
+		go func() {
+			log.Printf("Watching %s for changes", configPath)
+			if err := config.Watch(ctx, configPath, func() { reload("file changed") }); err != nil {
+				log.Printf("Config watcher stopped: %v", err)
+			}
+		}()
+	}
+
+	go silences.Run(ctx)
+
+	if cfg.API.Enabled {
+		server := api.New(cfg.API, api.WithSilences(silences), api.WithPinger(sched), api.WithIncidents(sched))
+		go func() {
+			if err := server.Run(ctx); err != nil {
+				log.Printf("API server stopped: %v", err)
+			}
+		}()
+	}
+
+	if tg := cfg.Alerters.Telegram; tg.Enabled && tg.Commands {
+		go bot.New(tg, sched, silences).Run(ctx)
+	}
+
 	if err := sched.Start(ctx); err != nil {
 		log.Printf("Scheduler error: %v", err)
 	}
 }
+
+// restartSections returns the config sections that differ between current and
+// next and only take effect after a restart.
+func restartSections(current, next *config.Config) []string {
+	sections := []struct {
+		name       string
+		prev, next any
+	}{
+		{"[app]", current.App, next.App},
+		{"[http]", current.HTTP, next.HTTP},
+		{"[retry]", current.Retry, next.Retry},
+		{"[api]", current.API, next.API},
+		{"[incidents]", current.Incidents, next.Incidents},
+		{"[alerters]", current.Alerters, next.Alerters},
+	}
+
+	var changed []string
+	for _, s := range sections {
+		if !reflect.DeepEqual(s.prev, s.next) {
+			changed = append(changed, s.name)
+		}
+	}
+	return changed
+}
+
+// proxySuffix returns the proxy a check went through as a log field.
+func proxySuffix(result domain.CheckResult) string {
+	if result.Proxy == "" {
+		return ""
+	}
+	return ", proxy=" + result.Proxy
+}
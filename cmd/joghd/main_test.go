@@ -0,0 +1,27 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/config"
+)
+
+func TestRestartSections(t *testing.T) {
+	current := config.Default()
+
+	next := config.Default()
+	next.Targets = nil
+	next.Maintenance = nil
+	if got := restartSections(&current, &next); len(got) != 0 {
+		t.Errorf("restartSections = %v for target changes only, want none", got)
+	}
+
+	next.HTTP.Timeout = time.Minute
+	next.Alerters.Telegram.Enabled = !current.Alerters.Telegram.Enabled
+	want := []string{"[http]", "[alerters]"}
+	if got := restartSections(&current, &next); !slices.Equal(got, want) {
+		t.Errorf("restartSections = %v, want %v", got, want)
+	}
+}
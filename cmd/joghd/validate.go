@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/raha-io/joghd/internal/config"
+)
+
+// runValidate implements the validate subcommand. It reports every problem in
+// the configuration and exits non-zero if any error was found, so it can gate CI.
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	configPath := fs.String("config", "config.toml", "Path to configuration file")
+	strict := fs.Bool("strict", false, "Treat warnings as errors")
+	printConfig := fs.Bool("print", true, "Print the effective configuration with secrets redacted")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: joghd validate [flags]\n\nValidate the configuration, including environment overrides.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	cfg, diags, err := config.Inspect(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	for _, d := range diags {
+		fmt.Fprintf(os.Stderr, "%-7s %s\n", d.Level, d)
+	}
+
+	if *printConfig {
+		out, err := config.Render(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: rendering config: %v\n", err)
+			return 1
+		}
+		fmt.Printf("# Effective configuration (%s)\n%s", *configPath, out)
+	}
+
+	errs, warnings := len(diags.Errors()), len(diags.Warnings())
+	fmt.Fprintf(os.Stderr, "%s: %d error(s), %d warning(s)\n", *configPath, errs, warnings)
+
+	if errs > 0 || (*strict && warnings > 0) {
+		return 1
+	}
+	return 0
+}
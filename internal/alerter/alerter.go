@@ -2,10 +2,15 @@ package alerter
 
 import (
 	"context"
+	"errors"
 
 	"github.com/raha-io/joghd/internal/domain"
 )
 
+// ErrSilenced is returned by alerters that deliberately did not deliver an
+// alert, e.g. because its target is in maintenance.
+var ErrSilenced = errors.New("alert silenced")
+
 // Alerter defines the interface for sending alerts.
 type Alerter interface {
 	// Send sends an alert notification.
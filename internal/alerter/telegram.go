@@ -3,49 +3,215 @@ package alerter
 import (
 	"context"
 	"fmt"
+	"html/template"
+	"log"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/raha-io/joghd/internal/config"
 	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/telegram"
 	"resty.dev/v3"
 )
 
-const telegramAPIURL = "https://api.telegram.org"
-
-// TelegramAlerter sends alerts via Telegram Bot API.
+// TelegramAlerter sends alerts via Telegram Bot API. It remembers the
+// message of each target's failure alert: on recovery, that message is edited
+// to show the recovery and the recovery alert is sent as a reply to it.
 type TelegramAlerter struct {
-	client   *resty.Client
-	botToken string
-	chatID   string
+	client    *resty.Client
+	apiURL    string
+	botToken  string
+	chatID    string
+	threadID  int64
+	threads   []config.TelegramThread
+	templates map[domain.AlertType]*template.Template
+
+	mu       sync.Mutex
+	failures map[string]telegramMessage // target name -> failure alert message
+}
+
+// telegramMessage is a sent alert message.
+type telegramMessage struct {
+	ID       int64
+	ThreadID int64
+	Text     string
+	Sent     time.Time
 }
 
-// NewTelegramAlerter creates a new Telegram alerter.
+// NewTelegramAlerter creates a new Telegram alerter. Templates that fail to
+// parse are logged and the built-in layout is used instead; config validation
+// reports them beforehand.
 func NewTelegramAlerter(cfg config.TelegramConfig) *TelegramAlerter {
-	return &TelegramAlerter{
-		client:   resty.New(),
-		botToken: cfg.BotToken,
-		chatID:   cfg.ChatID,
+	t := &TelegramAlerter{
+		client:    resty.New(),
+		apiURL:    strings.TrimSuffix(cfg.APIURL, "/"),
+		botToken:  cfg.BotToken,
+		chatID:    cfg.ChatID,
+		threadID:  cfg.ThreadID,
+		threads:   cfg.Threads,
+		templates: make(map[domain.AlertType]*template.Template),
+		failures:  make(map[string]telegramMessage),
 	}
+
+	for alertType, text := range cfg.Templates.ByType() {
+		tmpl, err := telegram.ParseTemplate(strings.ToLower(alertType.String()), text)
+		if err != nil {
+			log.Printf("Ignoring Telegram %s template: %v", alertType, err)
+			continue
+		}
+		t.templates[alertType] = tmpl
+	}
+
+	return t
 }
 
-// Send sends an alert via Telegram.
+// Send sends an alert via Telegram. Messages longer than Telegram allows are
+// split at line breaks and sent one after another.
 func (t *TelegramAlerter) Send(ctx context.Context, alert domain.Alert) error {
-	message := formatTelegramMessage(alert)
+	parts := telegram.Split(t.format(alert))
+
+	if alert.Type == domain.AlertTypeRecovery {
+		if failure, ok := t.takeFailure(alert.Target.Name); ok {
+			return t.sendRecovery(ctx, alert, failure, parts)
+		}
+	}
+
+	threadID := t.threadFor(alert.Target)
+	id, err := t.sendParts(ctx, parts, threadID, 0)
+	if err != nil {
+		return err
+	}
+
+	if alert.Type == domain.AlertTypeFailure {
+		t.mu.Lock()
+		t.failures[alert.Target.Name] = telegramMessage{ID: id, ThreadID: threadID, Text: parts[0], Sent: alert.Timestamp}
+		t.mu.Unlock()
+	}
+	return nil
+}
+
+// format renders an alert with its template, if configured, or the built-in
+// layout.
+func (t *TelegramAlerter) format(alert domain.Alert) string {
+	tmpl, ok := t.templates[alert.Type]
+	if !ok {
+		return formatTelegramMessage(alert)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, alert); err != nil {
+		log.Printf("Telegram %s template failed, using the built-in layout: %v", alert.Type, err)
+		return formatTelegramMessage(alert)
+	}
+	return sb.String()
+}
+
+// sendParts sends the parts of a message in order, the first one as a reply
+// to replyTo if set, and returns the ID of the first.
+func (t *TelegramAlerter) sendParts(ctx context.Context, parts []string, threadID, replyTo int64) (int64, error) {
+	var first int64
+	for i, part := range parts {
+		id, err := t.sendMessage(ctx, part, threadID, replyTo)
+		if err != nil {
+			return 0, err
+		}
+		if i == 0 {
+			first = id
+		}
+	}
+	return first, nil
+}
+
+// sendRecovery marks the failure message as recovered and replies to it with
+// the recovery alert. Failing to edit the message does not fail the alert.
+// The first line of the failure message is replaced with the recovery.
+func (t *TelegramAlerter) sendRecovery(ctx context.Context, alert domain.Alert, failure telegramMessage, parts []string) error {
+	after := formatDowntime(alert.Timestamp.Sub(failure.Sent))
+	header := fmt.Sprintf("🟢 <b>RECOVERED after %s</b>: %s", after, esc(alert.Target.Name))
+	_, body, _ := strings.Cut(failure.Text, "\n")
+	if err := t.editMessageText(ctx, failure.ID, header+"\n"+body); err != nil {
+		log.Printf("Failed to mark failure alert for %s as recovered: %v", alert.Target.Name, err)
+	}
+
+	_, err := t.sendParts(ctx, parts, failure.ThreadID, failure.ID)
+	return err
+}
+
+// takeFailure returns and forgets the failure alert message of a target.
+func (t *TelegramAlerter) takeFailure(name string) (telegramMessage, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	failure, ok := t.failures[name]
+	delete(t.failures, name)
+	return failure, ok
+}
+
+// threadFor returns the forum topic for alerts about target.
+func (t *TelegramAlerter) threadFor(target domain.Target) int64 {
+	if target.Name != "" {
+		for _, th := range t.threads {
+			if target.Matches(th.Targets, th.Labels) {
+				return th.ThreadID
+			}
+		}
+	}
+	return t.threadID
+}
+
+// sendMessage sends text to the alert chat, in a forum topic if threadID is
+// set and as a reply if replyTo is set, and returns the new message's ID.
+func (t *TelegramAlerter) sendMessage(ctx context.Context, text string, threadID, replyTo int64) (int64, error) {
+	body := map[string]interface{}{
+		"chat_id":    t.chatID,
+		"text":       text,
+		"parse_mode": "HTML",
+	}
+	if threadID != 0 {
+		body["message_thread_id"] = threadID
+	}
+	if replyTo != 0 {
+		body["reply_parameters"] = map[string]interface{}{
+			"message_id":                  replyTo,
+			"allow_sending_without_reply": true,
+		}
+	}
+
+	var result struct {
+		Result struct {
+			MessageID int64 `json:"message_id"`
+		} `json:"result"`
+	}
+	if err := t.call(ctx, "sendMessage", body, &result); err != nil {
+		return 0, fmt.Errorf("sending telegram message: %w", err)
+	}
+	return result.Result.MessageID, nil
+}
 
-	url := fmt.Sprintf("%s/bot%s/sendMessage", telegramAPIURL, t.botToken)
+func (t *TelegramAlerter) editMessageText(ctx context.Context, messageID int64, text string) error {
+	return t.call(ctx, "editMessageText", map[string]interface{}{
+		"chat_id":    t.chatID,
+		"message_id": messageID,
+		"text":       text,
+		"parse_mode": "HTML",
+	}, nil)
+}
 
-	resp, err := t.client.R().
+// call invokes a Bot API method and decodes its response into result, if set.
+func (t *TelegramAlerter) call(ctx context.Context, method string, body, result interface{}) error {
+	url := fmt.Sprintf("%s/bot%s/%s", t.apiURL, t.botToken, method)
+
+	req := t.client.R().
 		SetContext(ctx).
 		SetHeader("Content-Type", "application/json").
-		SetBody(map[string]interface{}{
-			"chat_id":    t.chatID,
-			"text":       message,
-			"parse_mode": "Markdown",
-		}).
-		Post(url)
+		SetBody(body)
+	if result != nil {
+		req.SetResult(result)
+	}
 
+	resp, err := req.Post(url)
 	if err != nil {
-		return fmt.Errorf("sending telegram message: %w", err)
+		return err
 	}
 
 	if resp.StatusCode() != 200 {
@@ -55,12 +221,34 @@ func (t *TelegramAlerter) Send(ctx context.Context, alert domain.Alert) error {
 	return nil
 }
 
+// formatDowntime renders an outage duration compactly, e.g. "12m" or "1h5m".
+func formatDowntime(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	s := strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+	if hours, ok := strings.CutSuffix(s, "h0m"); ok {
+		return hours + "h"
+	}
+	return s
+}
+
 // Name returns the alerter name.
 func (t *TelegramAlerter) Name() string {
 	return "telegram"
 }
 
+// formatTelegramMessage renders the built-in layout of an alert as
+// parse_mode HTML. Every value is escaped; errors are truncated to
+// telegram.MaxErrorLength.
 func formatTelegramMessage(alert domain.Alert) string {
+	switch alert.Type {
+	case domain.AlertTypeBurnRate:
+		return formatTelegramBurnRateMessage(alert)
+	case domain.AlertTypeSilenceSummary:
+		return formatTelegramSilenceSummaryMessage(alert)
+	}
+
 	icon := "🔴"
 	if alert.Type == domain.AlertTypeRecovery {
 		icon = "🟢"
@@ -71,20 +259,24 @@ func formatTelegramMessage(alert domain.Alert) string {
 		status = "RECOVERED"
 	}
 
+	if alert.Target.Push() {
+		return formatTelegramPushMessage(alert, icon, status)
+	}
+
 	msg := fmt.Sprintf(
-		"%s *%s*: %s\n\n"+
-			"*Target:* %s\n"+
-			"*URL:* `%s`\n"+
-			"*Expected:* %d\n"+
-			"*Actual:* %d\n"+
-			"*Latency:* %s\n"+
-			"*Attempts:* %d\n"+
-			"*Time:* %s",
+		"%s <b>%s</b>: %s\n\n"+
+			"<b>Target:</b> %s\n"+
+			"<b>URL:</b> <code>%s</code>\n"+
+			"<b>Expected:</b> %d\n"+
+			"<b>Actual:</b> %d\n"+
+			"<b>Latency:</b> %s\n"+
+			"<b>Attempts:</b> %d\n"+
+			"<b>Time:</b> %s",
 		icon,
 		status,
-		alert.Target.Name,
-		alert.Target.Name,
-		alert.Target.URL,
+		esc(alert.Target.Name),
+		esc(alert.Target.Name),
+		esc(alert.Target.URL),
 		alert.Target.ExpectedStatus,
 		alert.Result.ActualStatus,
 		alert.Result.Latency.Round(time.Millisecond),
@@ -92,9 +284,165 @@ func formatTelegramMessage(alert domain.Alert) string {
 		alert.Timestamp.Format("2006-01-02 15:04:05 MST"),
 	)
 
+	if len(alert.Result.Steps) > 0 {
+		msg += "\n<b>Steps:</b>"
+		for _, step := range alert.Result.Steps {
+			mark := "✅"
+			if step.Error != nil {
+				mark = "❌"
+			}
+			msg += fmt.Sprintf("\n%s %s: %d in %s", mark, esc(step.Name), step.ActualStatus, step.Latency.Round(time.Millisecond))
+		}
+		if alert.Result.FailedStep != "" && alert.Type == domain.AlertTypeFailure {
+			msg += fmt.Sprintf("\n<b>Failed step:</b> %s", esc(alert.Result.FailedStep))
+		}
+	}
+
+	if !alert.Result.Timing.IsZero() {
+		msg += fmt.Sprintf("\n<b>Timing:</b> <code>%s</code>", esc(alert.Result.Timing.String()))
+	}
+	if len(alert.Result.Redirects) > 0 {
+		hops := make([]string, 0, len(alert.Result.Redirects)+1)
+		for _, r := range alert.Result.Redirects {
+			hops = append(hops, fmt.Sprintf("%s (%d)", r.URL, r.StatusCode))
+		}
+		hops = append(hops, alert.Result.FinalURL)
+		msg += fmt.Sprintf("\n<b>Redirects:</b> <code>%s</code>", esc(strings.Join(hops, " → ")))
+	}
+	if alert.Result.Proxy != "" {
+		msg += fmt.Sprintf("\n<b>Proxy:</b> <code>%s</code>", esc(alert.Result.Proxy))
+	}
+
+	if alert.Result.Error != nil && alert.Type == domain.AlertTypeFailure {
+		label := "Error"
+		switch domain.ErrorClass(alert.Result.Error) {
+		case domain.ErrorClassAuth:
+			label = "Auth error"
+		case domain.ErrorClassProxy:
+			label = "Proxy error"
+		}
+		msg += fmt.Sprintf("\n<b>%s:</b> <code>%s</code>", label, escError(alert.Result.Error))
+	}
+	if len(alert.Suppressed) > 0 {
+		msg += fmt.Sprintf("\n<b>Suppressed dependents:</b> %s", esc(strings.Join(alert.Suppressed, ", ")))
+	}
+	if alert.IncidentID != "" {
+		msg += fmt.Sprintf("\n<b>Incident:</b> %s", esc(alert.IncidentID))
+	}
+
+	return msg
+}
+
+func formatTelegramPushMessage(alert domain.Alert, icon, status string) string {
+	msg := fmt.Sprintf(
+		"%s <b>%s</b>: %s\n\n"+
+			"<b>Target:</b> %s (push)\n"+
+			"<b>Expected:</b> a ping every %s (grace %s)\n"+
+			"<b>Time:</b> %s",
+		icon,
+		status,
+		esc(alert.Target.Name),
+		esc(alert.Target.Name),
+		alert.Target.Period,
+		alert.Target.Grace,
+		alert.Timestamp.Format("2006-01-02 15:04:05 MST"),
+	)
+
+	if alert.Result.RunDuration > 0 {
+		msg += fmt.Sprintf("\n<b>Run time:</b> %s", alert.Result.RunDuration.Round(time.Millisecond))
+	}
 	if alert.Result.Error != nil && alert.Type == domain.AlertTypeFailure {
-		msg += fmt.Sprintf("\n*Error:* `%s`", alert.Result.Error.Error())
+		msg += fmt.Sprintf("\n<b>Error:</b> <code>%s</code>", escError(alert.Result.Error))
+	}
+	if len(alert.Suppressed) > 0 {
+		msg += fmt.Sprintf("\n<b>Suppressed dependents:</b> %s", esc(strings.Join(alert.Suppressed, ", ")))
+	}
+	if alert.IncidentID != "" {
+		msg += fmt.Sprintf("\n<b>Incident:</b> %s", esc(alert.IncidentID))
+	}
+
+	return msg
+}
+
+func formatTelegramBurnRateMessage(alert domain.Alert) string {
+	burn := alert.BurnRate
+	status := alert.SLO
+
+	icon := "🟠"
+	if alert.Severity == domain.SeverityCritical {
+		icon = "🔥"
+	}
+
+	msg := fmt.Sprintf(
+		"%s <b>BUDGET BURN</b>: %s\n\n"+
+			"<b>Target:</b> %s\n"+
+			"<b>URL:</b> <code>%s</code>\n"+
+			"<b>Rule:</b> %s (%s/%s &gt; %.1fx)\n"+
+			"<b>Burn rate:</b> %.1fx (%s), %.1fx (%s)\n"+
+			"<b>SLO:</b> %.3f%% over %s\n"+
+			"<b>Availability:</b> %.3f%%\n"+
+			"<b>Budget remaining:</b> %.1f%%\n"+
+			"<b>Time:</b> %s",
+		icon,
+		esc(alert.Target.Name),
+		esc(alert.Target.Name),
+		esc(alert.Target.URL),
+		esc(burn.Rule),
+		burn.LongWindow,
+		burn.ShortWindow,
+		burn.Threshold,
+		burn.LongRate,
+		burn.LongWindow,
+		burn.ShortRate,
+		burn.ShortWindow,
+		status.Objective,
+		status.Window,
+		status.Availability*100,
+		status.BudgetRemaining*100,
+		alert.Timestamp.Format("2006-01-02 15:04:05 MST"),
+	)
+	if alert.IncidentID != "" {
+		msg += fmt.Sprintf("\n<b>Incident:</b> %s", esc(alert.IncidentID))
+	}
+
+	return msg
+}
+
+func formatTelegramSilenceSummaryMessage(alert domain.Alert) string {
+	summary := alert.Summary
+
+	msg := fmt.Sprintf(
+		"🔕 <b>SILENCE ENDED</b>: %s\n\n"+
+			"<b>Period:</b> %s – %s\n"+
+			"<b>Suppressed alerts:</b> %d",
+		esc(summary.Silence.Label()),
+		summary.Started.Format("2006-01-02 15:04 MST"),
+		summary.Ended.Format("2006-01-02 15:04 MST"),
+		len(summary.Alerts),
+	)
+	if summary.Silence.Comment != "" {
+		msg += fmt.Sprintf("\n<b>Comment:</b> %s", esc(summary.Silence.Comment))
+	}
+
+	for _, a := range summary.Alerts {
+		msg += fmt.Sprintf("\n• %s %s: %s", a.Timestamp.Format("15:04:05"), esc(a.Target.Name), a.Type)
+	}
+
+	if len(summary.Failing) > 0 {
+		msg += fmt.Sprintf("\n\n🔴 <b>Still failing:</b> %s", esc(strings.Join(summary.Failing, ", ")))
+	} else {
+		msg += "\n\n🟢 All affected targets recovered"
 	}
 
 	return msg
 }
+
+// esc escapes a value for parse_mode HTML.
+func esc(s string) string {
+	return telegram.Escape(s)
+}
+
+// escError escapes an error message, truncated to telegram.MaxErrorLength.
+func escError(err error) string {
+	return telegram.Escape(telegram.Truncate(err.Error(), telegram.MaxErrorLength))
+}
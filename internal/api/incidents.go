@@ -0,0 +1,125 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/incident"
+)
+
+// Incident is the JSON form of an incident, with its status ("open" or
+// "resolved") and how long it lasted so far.
+type Incident struct {
+	domain.Incident
+	Status   string `json:"status"`
+	Duration string `json:"duration"`
+}
+
+// IncidentUpdate is the request body for acknowledging an incident or adding
+// a note to it. By defaults to "api".
+type IncidentUpdate struct {
+	By      string `json:"by,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// listIncidents lists incidents newest first, optionally filtered by the
+// status and target query parameters.
+func (s *Server) listIncidents(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status != "" && status != "open" && status != "resolved" {
+		writeError(w, http.StatusBadRequest, `status must be "open" or "resolved"`)
+		return
+	}
+	target := r.URL.Query().Get("target")
+
+	now := time.Now()
+	out := []Incident{}
+	for _, inc := range s.incidents.Incidents() {
+		i := newIncident(inc, now)
+		if (status == "" || i.Status == status) && (target == "" || strings.EqualFold(inc.Target, target)) {
+			out = append(out, i)
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) getIncident(w http.ResponseWriter, r *http.Request) {
+	inc, err := s.incidents.Incident(r.PathValue("id"))
+	if err != nil {
+		writeIncidentError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newIncident(inc, time.Now()))
+}
+
+func (s *Server) ackIncident(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeIncidentUpdate(w, r)
+	if !ok {
+		return
+	}
+	inc, err := s.incidents.AckIncident(r.PathValue("id"), req.By)
+	if err != nil {
+		writeIncidentError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newIncident(inc, time.Now()))
+}
+
+func (s *Server) noteIncident(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeIncidentUpdate(w, r)
+	if !ok {
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		writeError(w, http.StatusBadRequest, "message must be set")
+		return
+	}
+	inc, err := s.incidents.NoteIncident(r.PathValue("id"), req.By, req.Message)
+	if err != nil {
+		writeIncidentError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newIncident(inc, time.Now()))
+}
+
+// decodeIncidentUpdate reads an optional IncidentUpdate body, writing the
+// error response if it is invalid.
+func decodeIncidentUpdate(w http.ResponseWriter, r *http.Request) (IncidentUpdate, bool) {
+	var req IncidentUpdate
+	if r.ContentLength != 0 {
+		if err := decode(w, r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return IncidentUpdate{}, false
+		}
+	}
+	if req.By == "" {
+		req.By = "api"
+	}
+	return req, true
+}
+
+func writeIncidentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, incident.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, incident.ErrResolved):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func newIncident(inc domain.Incident, now time.Time) Incident {
+	status := "resolved"
+	if inc.Open() {
+		status = "open"
+	}
+	return Incident{
+		Incident: inc,
+		Status:   status,
+		Duration: inc.Duration(now).Round(time.Second).String(),
+	}
+}
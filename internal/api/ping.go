@@ -0,0 +1,28 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// maxPingMessage is how much of a ping's request body is kept as its message.
+const maxPingMessage = 1000
+
+// ping returns the handler for pings of the given kind. Any method is
+// accepted, so jobs can ping with a plain GET or POST their output.
+func (s *Server) ping(kind domain.PingKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(io.LimitReader(r.Body, maxPingMessage))
+		message := strings.TrimSpace(strings.ToValidUTF8(string(body), ""))
+
+		if err := s.pinger.Ping(r.PathValue("token"), kind, message); err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, "OK\n")
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/raha-io/joghd/internal/config"
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/scheduler"
+)
+
+// pinger records the pings for its token.
+type pinger struct {
+	token string
+
+	mu    sync.Mutex
+	pings []domain.Ping
+}
+
+func (p *pinger) Ping(token string, kind domain.PingKind, message string) error {
+	if token != p.token {
+		return scheduler.ErrUnknownMonitor
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pings = append(p.pings, domain.Ping{Kind: kind, Message: message})
+	return nil
+}
+
+func TestPing(t *testing.T) {
+	p := &pinger{token: "nightly-7f3a"}
+	// Pings are authorized by their token, not the API token
+	srv := httptest.NewServer(New(config.APIConfig{Token: apiToken}, WithPinger(p)).Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		wantStatus  int
+		wantKind    domain.PingKind
+		wantMessage string
+	}{
+		{"success", http.MethodGet, "/ping/nightly-7f3a", "", http.StatusOK, domain.PingSuccess, ""},
+		{"start", http.MethodPost, "/ping/nightly-7f3a/start", "", http.StatusOK, domain.PingStart, ""},
+		{"fail with output", http.MethodPost, "/ping/nightly-7f3a/fail", "pg_dump: disk full\n", http.StatusOK, domain.PingFail, "pg_dump: disk full"},
+		{"long output", http.MethodPost, "/ping/nightly-7f3a/fail", strings.Repeat("x", 2*maxPingMessage), http.StatusOK, domain.PingFail, strings.Repeat("x", maxPingMessage)},
+		{"unknown token", http.MethodGet, "/ping/wrong", "", http.StatusNotFound, domain.PingSuccess, ""},
+	}
+	for _, tt := range tests {
+		p.pings = nil
+
+		req, err := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+		if tt.wantStatus != http.StatusOK {
+			if len(p.pings) != 0 {
+				t.Errorf("%s: delivered %v, want no ping", tt.name, p.pings)
+			}
+			continue
+		}
+		if len(p.pings) != 1 {
+			t.Errorf("%s: delivered %d pings, want 1", tt.name, len(p.pings))
+			continue
+		}
+		if got := p.pings[0]; got.Kind != tt.wantKind || got.Message != tt.wantMessage {
+			t.Errorf("%s: delivered %s ping %q, want %s ping %q", tt.name, got.Kind, got.Message, tt.wantKind, tt.wantMessage)
+		}
+	}
+}
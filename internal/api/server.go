@@ -0,0 +1,177 @@
+// Package api serves joghd's HTTP API in continuous mode.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/raha-io/joghd/internal/config"
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/silence"
+)
+
+// shutdownTimeout bounds how long Run waits for in-flight requests on exit.
+const shutdownTimeout = 5 * time.Second
+
+// Server is the HTTP API. Endpoints are registered for the components passed
+// as options. Management endpoints live under /api/v1 and require the API
+// token; push target pings live under /ping and are authorized by the target
+// token in the URL.
+type Server struct {
+	cfg       config.APIConfig
+	mux       *http.ServeMux
+	silences  *silence.Registry
+	pinger    Pinger
+	incidents Incidents
+}
+
+// Pinger delivers pings to push targets.
+type Pinger interface {
+	Ping(token string, kind domain.PingKind, message string) error
+}
+
+// Incidents reads incidents and adds acknowledgements and notes to them.
+type Incidents interface {
+	Incidents() []domain.Incident
+	Incident(id string) (domain.Incident, error)
+	AckIncident(id, by string) (domain.Incident, error)
+	NoteIncident(id, by, message string) (domain.Incident, error)
+}
+
+// Option is a functional option for configuring the server.
+type Option func(*Server)
+
+// WithSilences serves the silence endpoints backed by r.
+func WithSilences(r *silence.Registry) Option {
+	return func(s *Server) {
+		s.silences = r
+	}
+}
+
+// WithPinger serves the push target ping endpoints backed by p.
+func WithPinger(p Pinger) Option {
+	return func(s *Server) {
+		s.pinger = p
+	}
+}
+
+// WithIncidents serves the incident endpoints backed by i.
+func WithIncidents(i Incidents) Option {
+	return func(s *Server) {
+		s.incidents = i
+	}
+}
+
+// New creates an API server.
+func New(cfg config.APIConfig, opts ...Option) *Server {
+	s := &Server{
+		cfg: cfg,
+		mux: http.NewServeMux(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.silences != nil {
+		s.handle("GET /api/v1/silences", s.listSilences)
+		s.handle("POST /api/v1/silences", s.createSilence)
+		s.handle("DELETE /api/v1/silences/{id}", s.deleteSilence)
+	}
+	if s.incidents != nil {
+		s.handle("GET /api/v1/incidents", s.listIncidents)
+		s.handle("GET /api/v1/incidents/{id}", s.getIncident)
+		s.handle("POST /api/v1/incidents/{id}/ack", s.ackIncident)
+		s.handle("POST /api/v1/incidents/{id}/notes", s.noteIncident)
+	}
+	if s.pinger != nil {
+		s.mux.HandleFunc("/ping/{token}", s.ping(domain.PingSuccess))
+		s.mux.HandleFunc("/ping/{token}/start", s.ping(domain.PingStart))
+		s.mux.HandleFunc("/ping/{token}/fail", s.ping(domain.PingFail))
+	}
+
+	return s
+}
+
+// Handler returns the API handler.
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// handle registers a management endpoint, which requires the API token.
+func (s *Server) handle(pattern string, h http.HandlerFunc) {
+	s.mux.Handle(pattern, s.authenticate(h))
+}
+
+// Run serves the API on the configured address until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.cfg.Listen,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	log.Printf("API listening on %s", s.cfg.Listen)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// authenticate rejects requests without the configured bearer token.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.cfg.Token == "" {
+		return next
+	}
+	want := []byte("Bearer " + s.cfg.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// decode reads a JSON request body into v, rejecting unknown fields.
+func decode(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("API: writing response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": strings.TrimSpace(msg)})
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/silence"
+)
+
+// Silence is the JSON form of a silence or maintenance window. When creating
+// one, Duration ("2h") may be given instead of Ends and counts from Starts,
+// or from now if Starts is not set.
+type Silence struct {
+	ID        string            `json:"id,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	Targets   []string          `json:"targets,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Starts    *time.Time        `json:"starts,omitempty"`
+	Ends      *time.Time        `json:"ends,omitempty"`
+	Duration  string            `json:"duration,omitempty"`
+	Windows   []Window          `json:"windows,omitempty"`
+	TimeZone  string            `json:"timezone,omitempty"`
+	Comment   string            `json:"comment,omitempty"`
+	CreatedBy string            `json:"created_by,omitempty"`
+	Active    bool              `json:"active"`
+}
+
+// Window is the JSON form of a recurring time window.
+type Window struct {
+	Days  []string `json:"days,omitempty"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+func (s *Server) listSilences(w http.ResponseWriter, _ *http.Request) {
+	now := s.silences.Now()
+	out := []Silence{}
+	for _, sil := range s.silences.List() {
+		out = append(out, newSilence(sil, now))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) createSilence(w http.ResponseWriter, r *http.Request) {
+	var req Silence
+	if err := decode(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	sil, err := req.domain(s.silences.Now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sil, err = s.silences.Add(sil)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, newSilence(sil, s.silences.Now()))
+}
+
+func (s *Server) deleteSilence(w http.ResponseWriter, r *http.Request) {
+	switch err := s.silences.Remove(r.PathValue("id")); {
+	case errors.Is(err, silence.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, silence.ErrConfigured):
+		writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// domain converts a create request into a silence.
+func (req Silence) domain(now time.Time) (domain.Silence, error) {
+	sil := domain.Silence{
+		Name:      req.Name,
+		Targets:   req.Targets,
+		Labels:    req.Labels,
+		TimeZone:  req.TimeZone,
+		Comment:   req.Comment,
+		CreatedBy: req.CreatedBy,
+	}
+	if req.Starts != nil {
+		sil.Starts = *req.Starts
+	}
+	if req.Ends != nil {
+		sil.Ends = *req.Ends
+	}
+	for _, w := range req.Windows {
+		sil.Windows = append(sil.Windows, domain.Window(w))
+	}
+
+	if req.Duration != "" {
+		if req.Ends != nil {
+			return domain.Silence{}, errors.New("set either ends or duration, not both")
+		}
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil || d <= 0 {
+			return domain.Silence{}, errors.New("duration must be a positive duration such as \"2h\"")
+		}
+		from := now
+		if !sil.Starts.IsZero() {
+			from = sil.Starts
+		}
+		sil.Ends = from.Add(d)
+	}
+	return sil, nil
+}
+
+func newSilence(s domain.Silence, now time.Time) Silence {
+	out := Silence{
+		ID:        s.ID,
+		Name:      s.Name,
+		Targets:   s.Targets,
+		Labels:    s.Labels,
+		TimeZone:  s.TimeZone,
+		Comment:   s.Comment,
+		CreatedBy: s.CreatedBy,
+		Active:    s.Active(now),
+	}
+	if !s.Starts.IsZero() {
+		out.Starts = &s.Starts
+	}
+	if !s.Ends.IsZero() {
+		out.Ends = &s.Ends
+	}
+	for _, w := range s.Windows {
+		out.Windows = append(out.Windows, Window(w))
+	}
+	return out
+}
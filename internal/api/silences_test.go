@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/config"
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/fake"
+	"github.com/raha-io/joghd/internal/silence"
+)
+
+const apiToken = "s3cret"
+
+var epoch = time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+// silenceServer serves the silence endpoints for a registry on a fake clock,
+// with one configured maintenance window.
+func silenceServer(t *testing.T) (*httptest.Server, *fake.Clock) {
+	t.Helper()
+	clk := fake.NewClock(epoch)
+	reg := silence.New(fake.NewAlerter(), silence.WithClock(clk))
+	reg.Configure([]domain.Silence{{
+		Name:    "backup",
+		Targets: []string{"db"},
+		Windows: []domain.Window{{Start: "02:00", End: "03:00"}},
+	}})
+
+	srv := httptest.NewServer(New(config.APIConfig{Token: apiToken}, WithSilences(reg)).Handler())
+	t.Cleanup(srv.Close)
+	return srv, clk
+}
+
+func request(t *testing.T, method, url, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func decodeBody[T any](t *testing.T, resp *http.Response) T {
+	t.Helper()
+	var v T
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	return v
+}
+
+func TestCreateSilence(t *testing.T) {
+	srv, _ := silenceServer(t)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantEnds   time.Time
+		wantActive bool
+	}{
+		{"duration from now", `{"targets":["api"],"duration":"2h"}`, http.StatusCreated, epoch.Add(2 * time.Hour), true},
+		{"duration from starts", `{"targets":["api"],"starts":"2026-03-14T18:00:00Z","duration":"1h"}`,
+			http.StatusCreated, time.Date(2026, 3, 14, 19, 0, 0, 0, time.UTC), false},
+		{"ends", `{"labels":{"team":"payments"},"ends":"2026-03-14T13:00:00Z"}`, http.StatusCreated, epoch.Add(time.Hour), true},
+		{"ends and duration", `{"targets":["api"],"ends":"2026-03-14T13:00:00Z","duration":"1h"}`, http.StatusBadRequest, time.Time{}, false},
+		{"already ended", `{"targets":["api"],"ends":"2026-03-14T11:00:00Z"}`, http.StatusBadRequest, time.Time{}, false},
+		{"no targets", `{"duration":"1h"}`, http.StatusBadRequest, time.Time{}, false},
+		{"unknown field", `{"targets":["api"],"duration":"1h","until":"never"}`, http.StatusBadRequest, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		resp := request(t, http.MethodPost, srv.URL+"/api/v1/silences", tt.body)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.wantStatus)
+			continue
+		}
+		if tt.wantStatus != http.StatusCreated {
+			continue
+		}
+		got := decodeBody[Silence](t, resp)
+		if got.ID == "" {
+			t.Errorf("%s: created silence has no ID", tt.name)
+		}
+		if got.Ends == nil || !got.Ends.Equal(tt.wantEnds) {
+			t.Errorf("%s: ends %v, want %s", tt.name, got.Ends, tt.wantEnds)
+		}
+		if got.Active != tt.wantActive {
+			t.Errorf("%s: active %t, want %t", tt.name, got.Active, tt.wantActive)
+		}
+	}
+}
+
+func TestListSilencesUsesRegistryClock(t *testing.T) {
+	srv, clk := silenceServer(t)
+	request(t, http.MethodPost, srv.URL+"/api/v1/silences", `{"targets":["api"],"duration":"1h"}`)
+
+	active := func() map[string]bool {
+		out := make(map[string]bool)
+		for _, s := range decodeBody[[]Silence](t, request(t, http.MethodGet, srv.URL+"/api/v1/silences", "")) {
+			out[s.ID] = s.Active
+		}
+		return out
+	}
+
+	got := active()
+	if len(got) != 2 || got["backup"] {
+		t.Fatalf("silences at noon = %v, want the backup window inactive and the ad-hoc silence active", got)
+	}
+
+	// 02:30 the next day: the ad-hoc silence has ended, the window is open
+	clk.Set(time.Date(2026, 3, 15, 2, 30, 0, 0, time.UTC))
+	if got := active(); len(got) != 1 || !got["backup"] {
+		t.Errorf("silences at 02:30 = %v, want only the active backup window", got)
+	}
+}
+
+func TestDeleteSilence(t *testing.T) {
+	srv, _ := silenceServer(t)
+	created := decodeBody[Silence](t, request(t, http.MethodPost, srv.URL+"/api/v1/silences", `{"targets":["api"],"duration":"1h"}`))
+
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{created.ID, http.StatusNoContent},
+		{created.ID, http.StatusNotFound},
+		{"backup", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		if resp := request(t, http.MethodDelete, srv.URL+"/api/v1/silences/"+tt.id, ""); resp.StatusCode != tt.want {
+			t.Errorf("DELETE %s: status %d, want %d", tt.id, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestSilencesRequireToken(t *testing.T) {
+	srv, _ := silenceServer(t)
+
+	resp, err := http.Get(srv.URL + "/api/v1/silences")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
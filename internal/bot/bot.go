@@ -0,0 +1,274 @@
+// Package bot answers Telegram commands from on-call: live status, on-demand
+// checks, acknowledgements and mutes.
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raha-io/joghd/internal/clock"
+	"github.com/raha-io/joghd/internal/config"
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/telegram"
+	"resty.dev/v3"
+)
+
+const (
+	// pollTimeout is how long a getUpdates long poll waits for updates.
+	pollTimeout = 30 * time.Second
+	// retryWait is the pause after a failed getUpdates call.
+	retryWait = 5 * time.Second
+	// commandTimeout bounds how long a command, such as a slow /check, may
+	// take to run and reply.
+	commandTimeout = 2 * time.Minute
+)
+
+// Monitor is the live monitoring state the bot reads and controls.
+type Monitor interface {
+	Status() []domain.TargetStatus
+	Lookup(name string) (domain.Target, error)
+	Check(ctx context.Context, name string) (domain.CheckResult, error)
+	Ack(name, by string) (domain.TargetStatus, error)
+}
+
+// Silencer adds and looks up silences.
+type Silencer interface {
+	Add(s domain.Silence) (domain.Silence, error)
+	Silenced(target domain.Target) (domain.Silence, bool)
+}
+
+// Bot long-polls the Telegram Bot API for commands and answers them. Messages
+// from chats and users that are not allowed are ignored.
+type Bot struct {
+	client   *resty.Client
+	apiURL   string
+	botToken string
+	chats    []string
+	users    []int64
+	monitor  Monitor
+	silences Silencer
+	clock    clock.Clock
+}
+
+// Option is a functional option for configuring the bot.
+type Option func(*Bot)
+
+// WithClock sets the clock used for mutes and retry waits.
+func WithClock(clk clock.Clock) Option {
+	return func(b *Bot) {
+		b.clock = clk
+	}
+}
+
+// New creates a bot for the configured Telegram bot.
+func New(cfg config.TelegramConfig, monitor Monitor, silences Silencer, opts ...Option) *Bot {
+	chats := cfg.AllowedChatIDs
+	if len(chats) == 0 {
+		chats = []string{cfg.ChatID}
+	}
+
+	b := &Bot{
+		client:   resty.New().SetTimeout(pollTimeout + 10*time.Second),
+		apiURL:   strings.TrimSuffix(cfg.APIURL, "/"),
+		botToken: cfg.BotToken,
+		chats:    chats,
+		users:    cfg.AllowedUserIDs,
+		monitor:  monitor,
+		silences: silences,
+		clock:    clock.Real(),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Run answers commands until ctx is cancelled. Commands run concurrently, so
+// a slow one does not hold up the others, and Run waits for them to finish
+// before returning.
+func (b *Bot) Run(ctx context.Context) {
+	log.Println("Telegram bot listening for commands")
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	var offset int64
+	for {
+		updates, err := b.getUpdates(ctx, offset)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("Telegram bot: fetching updates: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.clock.After(retryWait):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil {
+				continue
+			}
+			wg.Add(1)
+			go func(msg message) {
+				defer wg.Done()
+				b.handle(ctx, msg)
+			}(*u.Message)
+		}
+	}
+}
+
+// handle answers a message if it is a command from an allowed chat and user.
+func (b *Bot) handle(ctx context.Context, msg message) {
+	name, args, ok := parseCommand(msg.Text)
+	if !ok {
+		return
+	}
+	if !b.allowed(msg) {
+		var from int64
+		if msg.From != nil {
+			from = msg.From.ID
+		}
+		log.Printf("Telegram bot: ignored /%s from user %d in chat %d (not allowed)", name, from, msg.Chat.ID)
+		return
+	}
+
+	log.Printf("Telegram bot: /%s %s from %s", name, strings.Join(args, " "), msg.From.name())
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	reply := b.run(ctx, name, args, msg.From.name())
+	if err := b.sendMessage(ctx, msg, reply); err != nil {
+		log.Printf("Telegram bot: replying to /%s: %v", name, err)
+	}
+}
+
+// allowed reports whether msg comes from an allowed chat and user.
+func (b *Bot) allowed(msg message) bool {
+	chatOK := slices.ContainsFunc(b.chats, func(id string) bool {
+		return id == strconv.FormatInt(msg.Chat.ID, 10) ||
+			(msg.Chat.Username != "" && strings.EqualFold(id, "@"+msg.Chat.Username))
+	})
+	if !chatOK || msg.From == nil {
+		return false
+	}
+	return len(b.users) == 0 || slices.Contains(b.users, msg.From.ID)
+}
+
+// parseCommand splits "/mute@joghd_bot api 2h" into "mute" and its arguments.
+func parseCommand(text string) (string, []string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil, false
+	}
+	name, _, _ := strings.Cut(strings.TrimPrefix(fields[0], "/"), "@")
+	return strings.ToLower(name), fields[1:], name != ""
+}
+
+// update, message, user and chat are the parts of the Bot API types the bot
+// uses.
+type update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *message `json:"message"`
+}
+
+type message struct {
+	MessageID       int64  `json:"message_id"`
+	MessageThreadID int64  `json:"message_thread_id"`
+	From            *user  `json:"from"`
+	Chat            chat   `json:"chat"`
+	Text            string `json:"text"`
+}
+
+type user struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+}
+
+// name returns how the user is shown in replies and acknowledgements.
+func (u *user) name() string {
+	switch {
+	case u == nil:
+		return "unknown"
+	case u.Username != "":
+		return "@" + u.Username
+	case u.FirstName != "":
+		return u.FirstName
+	default:
+		return strconv.FormatInt(u.ID, 10)
+	}
+}
+
+type chat struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type apiResponse[T any] struct {
+	OK          bool   `json:"ok"`
+	Result      T      `json:"result"`
+	Description string `json:"description"`
+}
+
+func (b *Bot) getUpdates(ctx context.Context, offset int64) ([]update, error) {
+	var out apiResponse[[]update]
+	resp, err := b.client.R().
+		SetContext(ctx).
+		SetBody(map[string]any{
+			"offset":          offset,
+			"timeout":         int(pollTimeout.Seconds()),
+			"allowed_updates": []string{"message"},
+		}).
+		SetResult(&out).
+		Post(b.method("getUpdates"))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != 200 || !out.OK {
+		return nil, fmt.Errorf("telegram API error: status %d, body: %s", resp.StatusCode(), resp.String())
+	}
+	return out.Result, nil
+}
+
+// sendMessage replies to msg in its chat and thread, in several messages if
+// text is longer than Telegram allows.
+func (b *Bot) sendMessage(ctx context.Context, msg message, text string) error {
+	for _, part := range telegram.SplitPlain(text) {
+		body := map[string]any{
+			"chat_id":          msg.Chat.ID,
+			"text":             part,
+			"reply_parameters": map[string]any{"message_id": msg.MessageID},
+		}
+		if msg.MessageThreadID != 0 {
+			body["message_thread_id"] = msg.MessageThreadID
+		}
+
+		resp, err := b.client.R().
+			SetContext(ctx).
+			SetBody(body).
+			Post(b.method("sendMessage"))
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode() != 200 {
+			return fmt.Errorf("telegram API error: status %d, body: %s", resp.StatusCode(), resp.String())
+		}
+	}
+	return nil
+}
+
+func (b *Bot) method(name string) string {
+	return fmt.Sprintf("%s/bot%s/%s", b.apiURL, b.botToken, name)
+}
@@ -0,0 +1,304 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/config"
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/fake"
+)
+
+const token = "123:abc"
+
+// telegramAPI is a stand-in for the Bot API. getUpdates long-polls for the
+// batches passed to send; sendMessage records the replies.
+type telegramAPI struct {
+	srv     *httptest.Server
+	updates chan []update
+	replies chan reply
+	nextID  int64
+}
+
+// reply is a sendMessage call.
+type reply struct {
+	ChatID          int64  `json:"chat_id"`
+	Text            string `json:"text"`
+	ReplyParameters struct {
+		MessageID int64 `json:"message_id"`
+	} `json:"reply_parameters"`
+}
+
+func newTelegramAPI(t *testing.T) *telegramAPI {
+	t.Helper()
+
+	api := &telegramAPI{updates: make(chan []update), replies: make(chan reply, 16)}
+	api.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/bot" + token + "/getUpdates":
+			// Reading the body lets the server notice when the bot hangs up
+			io.Copy(io.Discard, r.Body)
+			select {
+			case <-r.Context().Done():
+			case batch := <-api.updates:
+				json.NewEncoder(w).Encode(apiResponse[[]update]{OK: true, Result: batch})
+			}
+		case "/bot" + token + "/sendMessage":
+			var rep reply
+			if err := json.NewDecoder(r.Body).Decode(&rep); err != nil {
+				t.Errorf("decoding sendMessage: %v", err)
+			}
+			api.replies <- rep
+			w.Write([]byte(`{"ok":true,"result":{}}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(api.srv.Close)
+	return api
+}
+
+// send delivers messages to the bot's next getUpdates call and returns their
+// message IDs.
+func (api *telegramAPI) send(t *testing.T, msgs ...message) []int64 {
+	t.Helper()
+	batch := make([]update, len(msgs))
+	ids := make([]int64, len(msgs))
+	for i, msg := range msgs {
+		api.nextID++
+		msg.MessageID = api.nextID
+		batch[i] = update{UpdateID: api.nextID, Message: &msg}
+		ids[i] = api.nextID
+	}
+	select {
+	case api.updates <- batch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the bot is not polling for updates")
+	}
+	return ids
+}
+
+// reply waits for the bot's next reply.
+func (api *telegramAPI) reply(t *testing.T) reply {
+	t.Helper()
+	select {
+	case rep := <-api.replies:
+		return rep
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reply from the bot")
+		return reply{}
+	}
+}
+
+// monitor is a Monitor for one target. Check blocks until release is closed,
+// if set.
+type monitor struct {
+	target  domain.Target
+	release chan struct{}
+
+	mu    sync.Mutex
+	ackBy string
+}
+
+func (m *monitor) Status() []domain.TargetStatus {
+	return []domain.TargetStatus{{Target: m.target, Status: domain.StatusHealthy}}
+}
+
+func (m *monitor) Lookup(name string) (domain.Target, error) {
+	if !strings.EqualFold(name, m.target.Name) {
+		return domain.Target{}, errors.New("unknown target " + name)
+	}
+	return m.target, nil
+}
+
+func (m *monitor) Check(ctx context.Context, name string) (domain.CheckResult, error) {
+	if m.release != nil {
+		select {
+		case <-ctx.Done():
+			return domain.CheckResult{}, ctx.Err()
+		case <-m.release:
+		}
+	}
+	return domain.CheckResult{Target: m.target, Success: true, ActualStatus: 200}, nil
+}
+
+func (m *monitor) Ack(name, by string) (domain.TargetStatus, error) {
+	target, err := m.Lookup(name)
+	if err != nil {
+		return domain.TargetStatus{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ackBy = by
+	return domain.TargetStatus{Target: target, Status: domain.StatusUnhealthy, Incident: "INC-20260314-7KQ3"}, nil
+}
+
+// silencer records the silences it is asked to add.
+type silencer struct {
+	mu    sync.Mutex
+	added []domain.Silence
+}
+
+func (s *silencer) Add(silence domain.Silence) (domain.Silence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	silence.ID = "1"
+	s.added = append(s.added, silence)
+	return silence, nil
+}
+
+func (s *silencer) Silenced(domain.Target) (domain.Silence, bool) {
+	return domain.Silence{}, false
+}
+
+const (
+	chatID = 100
+	userID = 7
+)
+
+var alice = &user{ID: userID, Username: "alice"}
+
+// startBot runs a bot allowed to take commands from alice in chat 100 and
+// stops it when the test ends.
+func startBot(t *testing.T, api *telegramAPI, mon Monitor, sil Silencer, opts ...Option) {
+	t.Helper()
+
+	cfg := config.TelegramConfig{
+		BotToken:       token,
+		ChatID:         "100",
+		APIURL:         api.srv.URL,
+		AllowedUserIDs: []int64{userID},
+	}
+	b := New(cfg, mon, sil, opts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		b.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestAllowed(t *testing.T) {
+	b := New(config.TelegramConfig{
+		AllowedChatIDs: []string{"100", "@oncall"},
+		AllowedUserIDs: []int64{userID},
+	}, nil, nil)
+
+	tests := []struct {
+		name string
+		msg  message
+		want bool
+	}{
+		{"allowed chat and user", message{Chat: chat{ID: 100}, From: alice}, true},
+		{"allowed channel username", message{Chat: chat{ID: 200, Username: "OnCall"}, From: alice}, true},
+		{"other chat", message{Chat: chat{ID: 200}, From: alice}, false},
+		{"other user", message{Chat: chat{ID: 100}, From: &user{ID: 8}}, false},
+		{"no sender", message{Chat: chat{ID: 100}}, false},
+	}
+	for _, tt := range tests {
+		if got := b.allowed(tt.msg); got != tt.want {
+			t.Errorf("%s: allowed = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoresCommandsThatAreNotAllowed(t *testing.T) {
+	api := newTelegramAPI(t)
+	startBot(t, api, &monitor{target: domain.Target{Name: "API"}}, &silencer{})
+
+	api.send(t,
+		message{Chat: chat{ID: 200}, From: alice, Text: "/status"},
+		message{Chat: chat{ID: chatID}, From: &user{ID: 8}, Text: "/status"},
+		message{Chat: chat{ID: chatID}, Text: "/status"},
+	)
+	allowed := api.send(t, message{Chat: chat{ID: chatID}, From: alice, Text: "/status"})
+
+	rep := api.reply(t)
+	if rep.ReplyParameters.MessageID != allowed[0] {
+		t.Errorf("replied to message %d, want only the allowed message %d", rep.ReplyParameters.MessageID, allowed[0])
+	}
+	select {
+	case rep := <-api.replies:
+		t.Errorf("unexpected reply to message %d: %q", rep.ReplyParameters.MessageID, rep.Text)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMuteAddsSilence(t *testing.T) {
+	api := newTelegramAPI(t)
+	clk := fake.NewClock(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	sil := &silencer{}
+	startBot(t, api, &monitor{target: domain.Target{Name: "API"}}, sil, WithClock(clk))
+
+	api.send(t, message{Chat: chat{ID: chatID}, From: alice, Text: "/mute@joghd_bot api 2h"})
+	rep := api.reply(t)
+
+	sil.mu.Lock()
+	defer sil.mu.Unlock()
+	if len(sil.added) != 1 {
+		t.Fatalf("added %d silences, want 1", len(sil.added))
+	}
+	s := sil.added[0]
+	if len(s.Targets) != 1 || s.Targets[0] != "API" {
+		t.Errorf("silence targets = %v, want [API]", s.Targets)
+	}
+	if want := clk.Now().Add(2 * time.Hour); !s.Ends.Equal(want) {
+		t.Errorf("silence ends %s, want %s", s.Ends, want)
+	}
+	if s.CreatedBy != "@alice" {
+		t.Errorf("silence created by %q, want @alice", s.CreatedBy)
+	}
+	if !strings.Contains(rep.Text, "API muted until Jan 1 14:00 UTC by @alice") {
+		t.Errorf("reply = %q", rep.Text)
+	}
+}
+
+func TestAckAcknowledgesTarget(t *testing.T) {
+	api := newTelegramAPI(t)
+	mon := &monitor{target: domain.Target{Name: "API"}}
+	startBot(t, api, mon, &silencer{})
+
+	api.send(t, message{Chat: chat{ID: chatID}, From: alice, Text: "/ack API"})
+	rep := api.reply(t)
+
+	if want := "API acknowledged by @alice (INC-20260314-7KQ3)"; !strings.Contains(rep.Text, want) {
+		t.Errorf("reply = %q, want it to contain %q", rep.Text, want)
+	}
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+	if mon.ackBy != "@alice" {
+		t.Errorf("acknowledged by %q, want @alice", mon.ackBy)
+	}
+}
+
+func TestSlowCheckDoesNotBlockOtherCommands(t *testing.T) {
+	api := newTelegramAPI(t)
+	mon := &monitor{target: domain.Target{Name: "API"}, release: make(chan struct{})}
+	startBot(t, api, mon, &silencer{})
+
+	check := api.send(t, message{Chat: chat{ID: chatID}, From: alice, Text: "/check API"})
+	status := api.send(t, message{Chat: chat{ID: chatID}, From: alice, Text: "/status"})
+
+	if rep := api.reply(t); rep.ReplyParameters.MessageID != status[0] {
+		t.Fatalf("first reply is to message %d, want the /status message %d", rep.ReplyParameters.MessageID, status[0])
+	}
+	close(mon.release)
+	if rep := api.reply(t); rep.ReplyParameters.MessageID != check[0] {
+		t.Errorf("second reply is to message %d, want the /check message %d", rep.ReplyParameters.MessageID, check[0])
+	}
+}
@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+const help = `Commands:
+/status - health of all targets
+/check <target> - check a target now
+/ack <target> - acknowledge a failing target
+/mute <target> <duration> - mute alerts for a target, e.g. /mute API 2h`
+
+// run executes a command on behalf of who and returns the reply.
+func (b *Bot) run(ctx context.Context, name string, args []string, who string) string {
+	switch name {
+	case "status":
+		return b.status()
+	case "check":
+		if len(args) == 0 {
+			return "Usage: /check <target>"
+		}
+		return b.check(ctx, strings.Join(args, " "))
+	case "ack":
+		if len(args) == 0 {
+			return "Usage: /ack <target>"
+		}
+		st, err := b.monitor.Ack(strings.Join(args, " "), who)
+		if err != nil {
+			return errorReply(err)
+		}
+		reply := fmt.Sprintf("✅ %s acknowledged by %s", st.Target.Name, who)
+		if st.Incident != "" {
+			reply += " (" + st.Incident + ")"
+		}
+		return reply
+	case "mute":
+		if len(args) < 2 {
+			return "Usage: /mute <target> <duration>"
+		}
+		return b.mute(strings.Join(args[:len(args)-1], " "), args[len(args)-1], who)
+	case "start", "help":
+		return help
+	default:
+		return fmt.Sprintf("Unknown command /%s\n\n%s", name, help)
+	}
+}
+
+func (b *Bot) status() string {
+	statuses := b.monitor.Status()
+	if len(statuses) == 0 {
+		return "No targets configured"
+	}
+
+	now := b.clock.Now()
+	failing := 0
+	lines := make([]string, 0, len(statuses))
+	for _, st := range statuses {
+		if st.Status == domain.StatusPending || st.Status == domain.StatusUnhealthy {
+			failing++
+		}
+
+		line := fmt.Sprintf("%s %s: %s", statusIcon(st.Status), st.Target.Name, st.Status)
+		if !st.Since.IsZero() {
+			line += " for " + now.Sub(st.Since).Round(time.Second).String()
+		}
+		if st.Incident != "" {
+			line += " [" + st.Incident + "]"
+		}
+		if st.Last != nil && !st.Last.Success && st.Last.Error != nil {
+			line += " (" + st.Last.Error.Error() + ")"
+		}
+		if st.Ack != nil {
+			line += fmt.Sprintf("\n    acknowledged by %s at %s", st.Ack.By, st.Ack.At.Format("15:04"))
+		}
+		if s, ok := b.silences.Silenced(st.Target); ok {
+			line += "\n    🔕 muted"
+			if !s.Ends.IsZero() {
+				line += " until " + s.Ends.Format("Jan 2 15:04 MST")
+			}
+			line += " (" + s.Label() + ")"
+		}
+		lines = append(lines, line)
+	}
+
+	header := fmt.Sprintf("%d targets, %d failing", len(statuses), failing)
+	return header + "\n\n" + strings.Join(lines, "\n")
+}
+
+func (b *Bot) check(ctx context.Context, name string) string {
+	result, err := b.monitor.Check(ctx, name)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	if result.Success {
+		return fmt.Sprintf("🟢 %s: %d in %s", result.Target.Name, result.ActualStatus, result.Latency.Round(time.Millisecond))
+	}
+	reply := fmt.Sprintf("🔴 %s: failed after %d attempt(s)", result.Target.Name, result.Attempts)
+	if result.Error != nil {
+		reply += "\n" + result.Error.Error()
+	}
+	return reply
+}
+
+func (b *Bot) mute(name, duration, who string) string {
+	d, err := time.ParseDuration(duration)
+	if err != nil || d <= 0 {
+		return fmt.Sprintf("Invalid duration %q, use e.g. 30m or 2h", duration)
+	}
+	target, err := b.monitor.Lookup(name)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	s, err := b.silences.Add(domain.Silence{
+		Name:      "mute " + target.Name,
+		Targets:   []string{escapeGlob(target.Name)},
+		Ends:      b.clock.Now().Add(d),
+		Comment:   "muted via Telegram",
+		CreatedBy: who,
+	})
+	if err != nil {
+		return errorReply(err)
+	}
+	return fmt.Sprintf("🔕 %s muted until %s by %s", target.Name, s.Ends.Format("Jan 2 15:04 MST"), who)
+}
+
+func statusIcon(status domain.HealthStatus) string {
+	switch status {
+	case domain.StatusHealthy:
+		return "🟢"
+	case domain.StatusUnhealthy:
+		return "🔴"
+	case domain.StatusPending:
+		return "🟡"
+	case domain.StatusPaused:
+		return "⏸"
+	default:
+		return "⚪"
+	}
+}
+
+func errorReply(err error) string {
+	return "⚠️ " + err.Error()
+}
+
+// escapeGlob quotes the pattern characters of a silence target name so it
+// only matches itself.
+func escapeGlob(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if strings.ContainsRune(`*?[\`, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
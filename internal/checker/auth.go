@@ -0,0 +1,300 @@
+package checker
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raha-io/joghd/internal/clock"
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// tokenRefreshMargin is how long before expiry a cached token is refreshed.
+const tokenRefreshMargin = 30 * time.Second
+
+// authenticator applies target credentials to requests. OAuth2 tokens are
+// cached per token URL, client and scopes and refreshed before they expire.
+// Targets needing the same token while it is being fetched wait for that
+// fetch instead of starting their own.
+type authenticator struct {
+	client HTTPClient
+	clock  clock.Clock
+
+	mu       sync.Mutex
+	tokens   map[string]oauthToken
+	fetching map[string]*tokenFetch
+}
+
+type oauthToken struct {
+	value   string
+	expires time.Time
+}
+
+// tokenFetch is a token request in flight; done is closed once tok and err
+// are set.
+type tokenFetch struct {
+	done chan struct{}
+	tok  oauthToken
+	err  error
+}
+
+func newAuthenticator(client HTTPClient, clk clock.Clock) *authenticator {
+	return &authenticator{
+		client:   client,
+		clock:    clk,
+		tokens:   make(map[string]oauthToken),
+		fetching: make(map[string]*tokenFetch),
+	}
+}
+
+// apply adds credentials to req. Failures are wrapped in domain.AuthError.
+func (a *authenticator) apply(ctx context.Context, auth domain.Auth, req *Request) error {
+	var err error
+	switch auth.Type {
+	case "":
+		return nil
+	case domain.AuthBasic:
+		err = a.applyBasic(auth, req)
+	case domain.AuthOAuth2:
+		err = a.applyOAuth2(ctx, auth, req)
+	case domain.AuthHMAC:
+		err = a.applyHMAC(auth, req)
+	default:
+		err = fmt.Errorf("unsupported auth type %q", auth.Type)
+	}
+	if err != nil {
+		return &domain.AuthError{Err: err}
+	}
+	return nil
+}
+
+// invalidate drops the cached OAuth2 token so the next request fetches a new
+// one, e.g. after the target rejected it.
+func (a *authenticator) invalidate(auth domain.Auth) {
+	if auth.Type != domain.AuthOAuth2 {
+		return
+	}
+	a.mu.Lock()
+	delete(a.tokens, tokenKey(auth))
+	a.mu.Unlock()
+}
+
+func (a *authenticator) applyBasic(auth domain.Auth, req *Request) error {
+	password, err := resolveSecret(auth.Password)
+	if err != nil {
+		return fmt.Errorf("password: %w", err)
+	}
+	creds := base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + password))
+	setHeader(req, "Authorization", "Basic "+creds)
+	return nil
+}
+
+func (a *authenticator) applyOAuth2(ctx context.Context, auth domain.Auth, req *Request) error {
+	tok, err := a.token(ctx, auth, *req)
+	if err != nil {
+		return fmt.Errorf("fetching oauth2 token: %w", err)
+	}
+
+	setHeader(req, "Authorization", "Bearer "+tok.value)
+	return nil
+}
+
+// token returns the cached token for auth, fetching a new one if it is
+// missing or about to expire. Concurrent calls for the same token share one
+// fetch.
+func (a *authenticator) token(ctx context.Context, auth domain.Auth, req Request) (oauthToken, error) {
+	key := tokenKey(auth)
+
+	a.mu.Lock()
+	if tok, ok := a.tokens[key]; ok && tok.expires.Sub(a.clock.Now()) >= tokenRefreshMargin {
+		a.mu.Unlock()
+		return tok, nil
+	}
+	f, inFlight := a.fetching[key]
+	if !inFlight {
+		f = &tokenFetch{done: make(chan struct{})}
+		a.fetching[key] = f
+	}
+	a.mu.Unlock()
+
+	if inFlight {
+		select {
+		case <-f.done:
+			return f.tok, f.err
+		case <-ctx.Done():
+			return oauthToken{}, ctx.Err()
+		}
+	}
+
+	// The fetch is bounded by the request timeout and must not fail for the
+	// waiting targets if this one's check is cancelled
+	f.tok, f.err = a.fetchToken(context.WithoutCancel(ctx), auth, req)
+
+	a.mu.Lock()
+	delete(a.fetching, key)
+	if f.err == nil {
+		a.tokens[key] = f.tok
+	}
+	a.mu.Unlock()
+	close(f.done)
+
+	return f.tok, f.err
+}
+
+// fetchToken performs the OAuth2 client credentials grant (RFC 6749 §4.4).
+// The token endpoint is reached with the target request's timeout, TLS, proxy
+// and redirect settings.
+func (a *authenticator) fetchToken(ctx context.Context, auth domain.Auth, target Request) (oauthToken, error) {
+	secret, err := resolveSecret(auth.ClientSecret)
+	if err != nil {
+		return oauthToken{}, fmt.Errorf("client_secret: %w", err)
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(auth.Scopes) > 0 {
+		form.Set("scope", strings.Join(auth.Scopes, " "))
+	}
+	creds := base64.StdEncoding.EncodeToString(
+		[]byte(url.QueryEscape(auth.ClientID) + ":" + url.QueryEscape(secret)))
+
+	resp, err := a.client.Execute(ctx, Request{
+		Method:      http.MethodPost,
+		URL:         auth.TokenURL,
+		Headers:     map[string]string{"Authorization": "Basic " + creds, "Accept": "application/json"},
+		Timeout:     target.Timeout,
+		Body:        []byte(form.Encode()),
+		ContentType: "application/x-www-form-urlencoded",
+		KeepAlive:   true,
+		TLS:         target.TLS,
+		Proxy:       target.Proxy,
+
+		FollowRedirects: target.FollowRedirects,
+		MaxRedirects:    target.MaxRedirects,
+	})
+	if err != nil {
+		return oauthToken{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return oauthToken{}, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken string          `json:"access_token"`
+		ExpiresIn   json.RawMessage `json:"expires_in"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return oauthToken{}, fmt.Errorf("decoding token response: %w", err)
+	}
+	if body.AccessToken == "" {
+		return oauthToken{}, fmt.Errorf("token response has no access_token")
+	}
+
+	tok := oauthToken{value: body.AccessToken, expires: a.clock.Now().Add(time.Hour)}
+	// Some providers send expires_in as a string
+	if s := strings.Trim(string(body.ExpiresIn), `"`); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil && secs > 0 {
+			tok.expires = a.clock.Now().Add(time.Duration(secs) * time.Second)
+		}
+	}
+	return tok, nil
+}
+
+// applyHMAC signs the request. The signature is the hex HMAC of
+//
+//	METHOD \n PATH?QUERY \n UNIX-TIMESTAMP \n hex(SHA-256(body))
+//
+// sent in the configured header (default X-Signature) along with
+// X-Timestamp and, if set, X-Key-Id.
+func (a *authenticator) applyHMAC(auth domain.Auth, req *Request) error {
+	secret, err := resolveSecret(auth.Secret)
+	if err != nil {
+		return fmt.Errorf("secret: %w", err)
+	}
+
+	var newHash func() hash.Hash
+	switch strings.ToLower(auth.Algorithm) {
+	case "", "sha256":
+		newHash = sha256.New
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return fmt.Errorf("unsupported hmac algorithm %q", auth.Algorithm)
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return fmt.Errorf("parsing url: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(a.clock.Now().Unix(), 10)
+	bodyHash := sha256.Sum256(req.Body)
+	payload := strings.Join([]string{
+		strings.ToUpper(req.Method),
+		u.RequestURI(),
+		timestamp,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write([]byte(payload))
+
+	header := auth.Header
+	if header == "" {
+		header = "X-Signature"
+	}
+	setHeader(req, header, hex.EncodeToString(mac.Sum(nil)))
+	setHeader(req, "X-Timestamp", timestamp)
+	if auth.KeyID != "" {
+		setHeader(req, "X-Key-Id", auth.KeyID)
+	}
+	return nil
+}
+
+func tokenKey(auth domain.Auth) string {
+	return auth.TokenURL + "\x00" + auth.ClientID + "\x00" + strings.Join(auth.Scopes, " ")
+}
+
+// setHeader sets a header on a copy of the request headers, since they may be
+// shared with the target configuration.
+func setHeader(req *Request, key, value string) {
+	headers := maps.Clone(req.Headers)
+	if headers == nil {
+		headers = make(map[string]string, 1)
+	}
+	headers[key] = value
+	req.Headers = headers
+}
+
+// resolveSecret returns the value of a secret reference: "env:NAME" reads an
+// environment variable, "file:/path" reads a file, anything else is literal.
+func resolveSecret(ref string) (string, error) {
+	if name, ok := strings.CutPrefix(ref, "env:"); ok {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+	}
+	if path, ok := strings.CutPrefix(ref, "file:"); ok {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	return ref, nil
+}
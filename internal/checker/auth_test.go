@@ -0,0 +1,84 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/clock"
+	"github.com/raha-io/joghd/internal/config"
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+func TestOAuth2ConcurrentRequestsShareOneTokenFetch(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"tok","expires_in":3600}`))
+	}))
+	defer srv.Close()
+
+	a := newAuthenticator(NewRestyClient(config.Default().HTTP), clock.Real())
+	auth := domain.Auth{Type: domain.AuthOAuth2, TokenURL: srv.URL, ClientID: "id", ClientSecret: "secret"}
+
+	const n = 10
+	var wg sync.WaitGroup
+	headers := make([]string, n)
+	errs := make([]error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := Request{Method: http.MethodGet, URL: "http://example.com", Timeout: 5 * time.Second}
+			errs[i] = a.apply(context.Background(), auth, &req)
+			headers[i] = req.Headers["Authorization"]
+		}()
+	}
+
+	// Let every goroutine reach the token cache before the fetch completes
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("token endpoint hit %d times, want 1", got)
+	}
+	for i := range n {
+		if errs[i] != nil {
+			t.Fatalf("request %d: %v", i, errs[i])
+		}
+		if headers[i] != "Bearer tok" {
+			t.Errorf("request %d: Authorization = %q, want %q", i, headers[i], "Bearer tok")
+		}
+	}
+}
+
+func TestOAuth2TokenFetchUsesTargetTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"tok"}`))
+	}))
+	defer srv.Close()
+
+	a := newAuthenticator(NewRestyClient(config.Default().HTTP), clock.Real())
+	auth := domain.Auth{Type: domain.AuthOAuth2, TokenURL: srv.URL, ClientID: "id", ClientSecret: "secret"}
+
+	// The test server's certificate is self-signed, so the fetch only
+	// succeeds if it inherits the target's TLS settings
+	req := Request{
+		Method:  http.MethodGet,
+		URL:     "https://example.com",
+		Timeout: 5 * time.Second,
+		TLS:     domain.TLS{InsecureSkipVerify: true},
+	}
+	if err := a.apply(context.Background(), auth, &req); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+}
@@ -1,11 +1,15 @@
 package checker
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"sync"
 	"time"
 
+	"github.com/raha-io/joghd/internal/clock"
 	"github.com/raha-io/joghd/internal/config"
 	"github.com/raha-io/joghd/internal/domain"
 )
@@ -21,6 +25,8 @@ type checker struct {
 	httpClient  HTTPClient
 	retryConfig config.RetryConfig
 	concurrency int
+	clock       clock.Clock
+	auth        *authenticator
 }
 
 // Option is a functional option for configuring the checker.
@@ -47,6 +53,13 @@ func WithConcurrency(n int) Option {
 	}
 }
 
+// WithClock sets the clock used for timestamps and retry backoff.
+func WithClock(clk clock.Clock) Option {
+	return func(c *checker) {
+		c.clock = clk
+	}
+}
+
 // New creates a new Checker with the given options.
 func New(opts ...Option) Checker {
 	c := &checker{
@@ -57,12 +70,15 @@ func New(opts ...Option) Checker {
 			Multiplier:  2.0,
 		},
 		concurrency: 10,
+		clock:       clock.Real(),
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	c.auth = newAuthenticator(c.httpClient, c.clock)
+
 	return c
 }
 
@@ -70,7 +86,7 @@ func New(opts ...Option) Checker {
 func (c *checker) Check(ctx context.Context, target domain.Target) domain.CheckResult {
 	result := domain.CheckResult{
 		Target:    target,
-		Timestamp: time.Now(),
+		Timestamp: c.clock.Now(),
 	}
 
 	wait := c.retryConfig.InitialWait
@@ -84,36 +100,25 @@ func (c *checker) Check(ctx context.Context, target domain.Target) domain.CheckR
 		default:
 		}
 
-		statusCode, latency, err := c.httpClient.Execute(
-			ctx,
-			target.Method,
-			target.URL,
-			target.Headers,
-			target.Timeout,
-		)
-
 		result.Attempts = attempt
-		result.Latency = latency
-		result.ActualStatus = statusCode
+		if len(target.Steps) > 0 {
+			c.probeSteps(ctx, target, &result)
+		} else {
+			c.probe(ctx, target, &result)
+		}
 
-		if err == nil && statusCode == target.ExpectedStatus {
+		if result.Error == nil {
 			result.Success = true
 			return result
 		}
 
-		if err != nil {
-			result.Error = err
-		} else {
-			result.Error = fmt.Errorf("status mismatch: expected %d, got %d", target.ExpectedStatus, statusCode)
-		}
-
 		// Don't wait after the last attempt
 		if attempt < c.retryConfig.MaxAttempts {
 			select {
 			case <-ctx.Done():
 				result.Error = ctx.Err()
 				return result
-			case <-time.After(wait):
+			case <-c.clock.After(wait):
 			}
 
 			// Exponential backoff
@@ -148,7 +153,7 @@ func (c *checker) CheckAll(ctx context.Context, targets []domain.Target) []domai
 				results[idx] = domain.CheckResult{
 					Target:    t,
 					Error:     ctx.Err(),
-					Timestamp: time.Now(),
+					Timestamp: c.clock.Now(),
 				}
 				return
 			}
@@ -160,3 +165,100 @@ func (c *checker) CheckAll(ctx context.Context, targets []domain.Target) []domai
 	wg.Wait()
 	return results
 }
+
+// probe performs a single request against the target and records its outcome
+// in result. result.Error is nil if the target is healthy.
+func (c *checker) probe(ctx context.Context, target domain.Target, result *domain.CheckResult) {
+	req, err := newRequest(target)
+	if err != nil {
+		result.Error = err
+		return
+	}
+	if err := c.auth.apply(ctx, target.Auth, &req); err != nil {
+		result.Error = err
+		return
+	}
+
+	resp, err := c.httpClient.Execute(ctx, req)
+	if resp.StatusCode == http.StatusUnauthorized {
+		c.auth.invalidate(target.Auth)
+	}
+
+	result.Latency = resp.Latency
+	result.Timing = resp.Timing
+	result.ActualStatus = resp.StatusCode
+	result.Proxy = resp.Proxy
+	result.Redirects = resp.Redirects
+	result.FinalURL = resp.FinalURL
+	result.Error = verify(resp, err, target.ExpectedStatus, "", target.MaxLatency)
+	if result.Error == nil && target.ExpectedFinalURL != "" {
+		result.Error = checkFinalURL(target.ExpectedFinalURL, resp.FinalURL)
+	}
+}
+
+// verify checks a response against the expected status, body content and
+// latency limits.
+func verify(resp Response, err error, expectedStatus int, bodyContains string, limits domain.LatencyLimits) error {
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != expectedStatus {
+		return fmt.Errorf("status mismatch: expected %d, got %d", expectedStatus, resp.StatusCode)
+	}
+	if bodyContains != "" && !bytes.Contains(resp.Body, []byte(bodyContains)) {
+		return fmt.Errorf("body does not contain %q", bodyContains)
+	}
+	return limits.Check(resp.Latency, resp.Timing)
+}
+
+// newRequest builds the HTTP probe for a target.
+func newRequest(target domain.Target) (Request, error) {
+	body, contentType, err := targetPayload(target).build()
+	if err != nil {
+		return Request{}, err
+	}
+
+	return Request{
+		Method:      target.Method,
+		URL:         target.URL,
+		Headers:     target.Headers,
+		Timeout:     target.Timeout,
+		Body:        body,
+		ContentType: contentType,
+		KeepAlive:   keepAlive(target),
+		TLS:         target.TLS,
+		Proxy:       target.Proxy,
+
+		FollowRedirects: followRedirects(target),
+		MaxRedirects:    maxRedirects(target),
+	}, nil
+}
+
+func keepAlive(target domain.Target) bool {
+	return target.KeepAlive == nil || *target.KeepAlive
+}
+
+func followRedirects(target domain.Target) bool {
+	return target.FollowRedirects == nil || *target.FollowRedirects
+}
+
+func maxRedirects(target domain.Target) int {
+	if target.MaxRedirects == nil {
+		return domain.DefaultMaxRedirects
+	}
+	return *target.MaxRedirects
+}
+
+// checkFinalURL compares the URL a check ended up at with the expected one.
+// An expected value without scheme and host only has to match the path.
+func checkFinalURL(expected, final string) error {
+	if final == expected {
+		return nil
+	}
+	if u, err := url.Parse(expected); err == nil && u.Scheme == "" && u.Host == "" {
+		if f, err := url.Parse(final); err == nil && f.Path == u.Path {
+			return nil
+		}
+	}
+	return fmt.Errorf("final url mismatch: expected %s, got %s", expected, final)
+}
@@ -0,0 +1,56 @@
+package checker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/checker"
+	"github.com/raha-io/joghd/internal/config"
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/fake"
+)
+
+func TestRetryBackoffRunsOnFakeClock(t *testing.T) {
+	const url = "https://api.example.com/health"
+	clk := fake.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	prober := fake.NewProber(clk).Script(url, fake.Status(500), fake.Status(500), fake.Status(200))
+	chk := checker.New(
+		checker.WithHTTPClient(prober),
+		checker.WithClock(clk),
+		checker.WithRetryConfig(config.RetryConfig{
+			MaxAttempts: 3,
+			InitialWait: 10 * time.Second,
+			MaxWait:     15 * time.Second,
+			Multiplier:  2,
+		}),
+	)
+
+	target := domain.Target{Name: "API", URL: url, Method: "GET", ExpectedStatus: 200, Timeout: 5 * time.Second}
+	done := make(chan domain.CheckResult)
+	go func() {
+		done <- chk.Check(context.Background(), target)
+	}()
+
+	// The first retry waits 10s, the second 20s capped to max_wait
+	for i, wait := range []time.Duration{10 * time.Second, 15 * time.Second} {
+		clk.BlockUntil(1)
+		if got := prober.Calls(url); got != i+1 {
+			t.Fatalf("attempts before retry %d = %d, want %d", i+1, got, i+1)
+		}
+
+		clk.Advance(wait - time.Second)
+		if got := prober.Calls(url); got != i+1 {
+			t.Fatalf("retry %d ran before its %s backoff", i+1, wait)
+		}
+		clk.Advance(time.Second)
+	}
+
+	result := <-done
+	if !result.Success || result.Attempts != 3 {
+		t.Errorf("got success=%t after %d attempts, want success after 3: %v", result.Success, result.Attempts, result.Error)
+	}
+	if got := clk.Since(result.Timestamp); got != 25*time.Second {
+		t.Errorf("check took %s on the fake clock, want 25s", got)
+	}
+}
@@ -2,62 +2,309 @@ package checker
 
 import (
 	"context"
-	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/raha-io/joghd/internal/config"
+	"github.com/raha-io/joghd/internal/domain"
 	"resty.dev/v3"
 )
 
 // HTTPClient abstracts HTTP operations for testability.
 type HTTPClient interface {
-	Execute(ctx context.Context, method, url string, headers map[string]string, timeout time.Duration) (statusCode int, latency time.Duration, err error)
+	Execute(ctx context.Context, req Request) (Response, error)
 }
 
-// RestyClient wraps resty for HTTP operations.
+// Request describes a single HTTP probe.
+type Request struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Timeout time.Duration
+
+	// Body is sent with ContentType unless the headers set a Content-Type.
+	Body        []byte
+	ContentType string
+
+	// KeepAlive reuses pooled connections. When false the request opens a
+	// fresh connection that is closed afterwards, so the latency includes the
+	// full TCP and TLS handshake.
+	KeepAlive bool
+
+	// TLS selects the client certificate, CA bundle and TLS options.
+	TLS domain.TLS
+
+	// Proxy selects the proxy the request is sent through.
+	Proxy domain.Proxy
+
+	// FollowRedirects follows up to MaxRedirects redirects; otherwise the
+	// redirect response is returned as is.
+	FollowRedirects bool
+	MaxRedirects    int
+}
+
+// Response is the outcome of a single HTTP probe.
+type Response struct {
+	StatusCode int
+	Latency    time.Duration
+	Timing     domain.Timing
+	Header     http.Header
+	Body       []byte
+
+	// Proxy is the proxy the request was sent through, without credentials.
+	Proxy string
+
+	// Redirects lists the redirect responses in order and FinalURL is the URL
+	// of the last request.
+	Redirects []domain.Redirect
+	FinalURL  string
+}
+
+// redirectPolicyKey carries a request's redirect settings to the client-wide
+// redirect policy, since resty only configures redirects per client.
+type redirectPolicyKey struct{}
+
+// redirectPolicy applies the FollowRedirects and MaxRedirects settings of the
+// request being redirected.
+func redirectPolicy(req *http.Request, via []*http.Request) error {
+	r, ok := req.Context().Value(redirectPolicyKey{}).(Request)
+	if !ok {
+		return nil
+	}
+	if !r.FollowRedirects {
+		return http.ErrUseLastResponse
+	}
+	if len(via) > r.MaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", r.MaxRedirects)
+	}
+	return nil
+}
+
+// RestyClient wraps resty for HTTP operations. Requests with the same TLS
+// settings and proxy share a pooled transport; cold requests use a separate
+// transport with keep-alives disabled.
 type RestyClient struct {
-	client *resty.Client
+	cfg config.HTTPConfig
+
+	mu      sync.Mutex
+	clients map[transportKey]*resty.Client
+}
+
+// transportKey identifies the transport a request needs.
+type transportKey struct {
+	keepAlive bool
+	tls       domain.TLS
+	proxy     string
 }
 
 // NewRestyClient creates a new HTTP client with the given configuration.
 func NewRestyClient(cfg config.HTTPConfig) *RestyClient {
+	return &RestyClient{
+		cfg:     cfg,
+		clients: make(map[transportKey]*resty.Client),
+	}
+}
+
+// client returns the resty client for key, creating its transport on first use.
+func (c *RestyClient) client(key transportKey) (*resty.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[key]; ok {
+		return client, nil
+	}
+
+	transport, err := newTransport(c.cfg, key)
+	if err != nil {
+		return nil, err
+	}
 	client := resty.New().
-		SetTimeout(cfg.Timeout).
-		SetHeader("User-Agent", cfg.UserAgent)
+		SetTransport(transport).
+		SetRedirectPolicy(resty.RedirectPolicyFunc(redirectPolicy)).
+		SetHeader("User-Agent", c.cfg.UserAgent)
+	c.clients[key] = client
+	return client, nil
+}
 
-	if cfg.SkipTLSVerification {
-		client.SetTLSClientConfig(&tls.Config{
-			InsecureSkipVerify: true,
-		})
+// Retain drops the pooled transports that no request of targets needs any
+// more, closing their idle connections. It is called when the targets are
+// reloaded, so transports of removed or changed targets are not kept forever.
+func (c *RestyClient) Retain(targets []domain.Target) {
+	used := make(map[transportKey]bool)
+	for _, t := range targets {
+		for _, req := range transportRequests(t) {
+			if key, _, err := requestKey(req); err == nil {
+				used[key] = true
+			}
+		}
 	}
 
-	return &RestyClient{client: client}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, client := range c.clients {
+		if !used[key] {
+			client.Client().CloseIdleConnections()
+			delete(c.clients, key)
+		}
+	}
 }
 
-// Execute performs an HTTP request and returns the status code, latency, and any error.
-func (c *RestyClient) Execute(ctx context.Context, method, url string, headers map[string]string, timeout time.Duration) (int, time.Duration, error) {
-	req := c.client.R().SetContext(ctx)
+// requestKey returns the transport key of req and the proxy it goes through,
+// nil for a direct connection.
+func requestKey(req Request) (transportKey, *url.URL, error) {
+	proxy, err := resolveProxy(req.Proxy, req.URL)
+	if err != nil {
+		return transportKey{}, nil, err
+	}
+	key := transportKey{keepAlive: req.KeepAlive, tls: req.TLS}
+	if proxy != nil {
+		key.proxy = proxy.String()
+	}
+	return key, proxy, nil
+}
 
-	if timeout > 0 {
-		// Create a new client with specific timeout for this request
-		reqClient := resty.New().SetTimeout(timeout)
-		req = reqClient.R().SetContext(ctx)
+// transportRequests returns requests with the transport settings of every
+// request checking target makes: the probe or its steps, and the OAuth2 token
+// fetch. Step URLs are taken unexpanded, which is enough to pick the proxy.
+func transportRequests(target domain.Target) []Request {
+	base := Request{URL: target.URL, KeepAlive: keepAlive(target), TLS: target.TLS, Proxy: target.Proxy}
+	reqs := []Request{base}
+	for _, step := range target.Steps {
+		req := base
+		req.URL = step.URL
+		if b, err := url.Parse(target.URL); err == nil && target.URL != "" {
+			if ref, err := url.Parse(step.URL); err == nil {
+				req.URL = b.ResolveReference(ref).String()
+			}
+		}
+		reqs = append(reqs, req)
+	}
+	if target.Auth.Type == domain.AuthOAuth2 {
+		req := base
+		req.URL = target.Auth.TokenURL
+		req.KeepAlive = true
+		reqs = append(reqs, req)
+	}
+	return reqs
+}
+
+func newTransport(cfg config.HTTPConfig, key transportKey) (*http.Transport, error) {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
 
-		// Copy headers from parent client
-		req.SetHeader("User-Agent", c.client.Header().Get("User-Agent"))
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		DisableKeepAlives:     !key.keepAlive,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
 	}
 
-	for k, v := range headers {
-		req.SetHeader(k, v)
+	if key.proxy != "" {
+		proxy, err := url.Parse(key.proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	settings := key.tls
+	settings.InsecureSkipVerify = settings.InsecureSkipVerify || cfg.SkipTLSVerification
+	if settings != (domain.TLS{}) {
+		tlsConfig, err := newTLSConfig(settings)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return transport, nil
+}
+
+// Execute performs an HTTP request and returns the status code and latency.
+// The per-request timeout is applied through the context.
+func (c *RestyClient) Execute(ctx context.Context, req Request) (Response, error) {
+	key, proxy, err := requestKey(req)
+	if err != nil {
+		return Response{}, &domain.ProxyError{Proxy: req.Proxy.URL, Err: err}
+	}
+
+	client, err := c.client(key)
+	if err != nil {
+		return Response{}, err
+	}
+
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = c.cfg.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	t := &tracer{}
+	ctx = httptrace.WithClientTrace(ctx, t.clientTrace())
+	ctx = context.WithValue(ctx, redirectPolicyKey{}, req)
+
+	r := client.R().SetContext(ctx)
+	if req.Body != nil {
+		r.SetBody(req.Body).
+			SetAllowMethodGetPayload(true).
+			SetAllowMethodDeletePayload(true)
+		if req.ContentType != "" {
+			r.SetContentType(req.ContentType)
+		}
+	}
+	for k, v := range req.Headers {
+		r.SetHeader(k, v)
 	}
 
 	start := time.Now()
-	resp, err := req.Execute(method, url)
-	latency := time.Since(start)
+	resp, err := r.Execute(req.Method, req.URL)
+	end := time.Now()
 
+	out := Response{Latency: end.Sub(start), Timing: t.timing(end), Proxy: proxyName(proxy)}
+	if resp != nil && resp.RawResponse != nil {
+		out.Redirects, out.FinalURL = redirectChain(resp.RawResponse)
+	}
 	if err != nil {
-		return 0, latency, err
+		// Without a connection the request never got past the proxy
+		if proxy != nil && !t.connected() {
+			err = &domain.ProxyError{Proxy: out.Proxy, Err: err}
+		}
+		return out, err
 	}
 
-	return resp.StatusCode(), latency, nil
+	out.StatusCode = resp.StatusCode()
+	out.Header = resp.Header()
+	out.Body = resp.Bytes()
+	if proxy != nil && out.StatusCode == http.StatusProxyAuthRequired {
+		return out, &domain.ProxyError{Proxy: out.Proxy, Err: errors.New(resp.Status())}
+	}
+	return out, nil
+}
+
+// redirectChain walks back from the final response to list the redirects that
+// led to it.
+func redirectChain(final *http.Response) ([]domain.Redirect, string) {
+	var chain []domain.Redirect
+	for r := final.Request.Response; r != nil; r = r.Request.Response {
+		chain = append(chain, domain.Redirect{URL: r.Request.URL.String(), StatusCode: r.StatusCode})
+	}
+	slices.Reverse(chain)
+	return chain, final.Request.URL.String()
 }
@@ -0,0 +1,45 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/config"
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+func TestRetainEvictsUnusedTransports(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	cold := false
+	warm := domain.Target{Name: "Warm", URL: srv.URL, Method: http.MethodGet, Timeout: 5 * time.Second}
+	coldTarget := domain.Target{Name: "Cold", URL: srv.URL, Method: http.MethodGet, Timeout: 5 * time.Second, KeepAlive: &cold}
+
+	c := NewRestyClient(config.Default().HTTP)
+	for _, target := range []domain.Target{warm, coldTarget} {
+		req, err := newRequest(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Execute(context.Background(), req); err != nil {
+			t.Fatalf("%s: %v", target.Name, err)
+		}
+	}
+	if got := len(c.clients); got != 2 {
+		t.Fatalf("pooled %d transports, want 2", got)
+	}
+
+	// The cold target was removed by a reload
+	c.Retain([]domain.Target{warm})
+
+	if got := len(c.clients); got != 1 {
+		t.Fatalf("kept %d transports, want 1", got)
+	}
+	if _, ok := c.clients[transportKey{keepAlive: true}]; !ok {
+		t.Error("evicted the transport of the remaining target")
+	}
+}
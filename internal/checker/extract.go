@@ -0,0 +1,135 @@
+package checker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// extract evaluates an extraction against a step response.
+func extract(e domain.Extraction, header http.Header, body []byte) (string, error) {
+	switch {
+	case e.Header != "":
+		v := header.Get(e.Header)
+		if v == "" {
+			return "", fmt.Errorf("header %s not found", e.Header)
+		}
+		return v, nil
+
+	case e.Regex != "":
+		re, err := regexp.Compile(e.Regex)
+		if err != nil {
+			return "", fmt.Errorf("compiling regex: %w", err)
+		}
+		m := re.FindSubmatch(body)
+		if m == nil {
+			return "", fmt.Errorf("regex %q did not match", e.Regex)
+		}
+		if len(m) > 1 {
+			return string(m[1]), nil
+		}
+		return string(m[0]), nil
+
+	case e.JSONPath != "":
+		var doc any
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return "", fmt.Errorf("decoding json: %w", err)
+		}
+		v, err := jsonPath(doc, e.JSONPath)
+		if err != nil {
+			return "", err
+		}
+		switch v := v.(type) {
+		case string:
+			return v, nil
+		case nil:
+			return "", fmt.Errorf("json path %s is null", e.JSONPath)
+		default:
+			b, err := json.Marshal(v)
+			return string(b), err
+		}
+	}
+
+	return "", fmt.Errorf("no extraction source")
+}
+
+// jsonPath resolves a simple JSONPath expression such as $.data.items[0].id
+// or $['data']['token'] against a decoded JSON document. Filters, wildcards
+// and recursive descent are not supported.
+func jsonPath(doc any, path string) (any, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cur := doc
+	for _, seg := range segments {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[seg]
+			if !ok {
+				return nil, fmt.Errorf("json path %s: key %q not found", path, seg)
+			}
+			cur = v
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil {
+				return nil, fmt.Errorf("json path %s: %q is not an array index", path, seg)
+			}
+			if i < 0 {
+				i += len(node)
+			}
+			if i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("json path %s: index %s out of range", path, seg)
+			}
+			cur = node[i]
+		default:
+			return nil, fmt.Errorf("json path %s: cannot index into %T", path, cur)
+		}
+	}
+	return cur, nil
+}
+
+func parseJSONPath(path string) ([]string, error) {
+	p := strings.TrimPrefix(strings.TrimSpace(path), "$")
+
+	var segments []string
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid json path %q", path)
+			}
+			segments = append(segments, p[:end])
+			p = p[end:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: unclosed bracket", path)
+			}
+			segments = append(segments, strings.Trim(p[1:end], `'"`))
+			p = p[end+1:]
+		default:
+			// Allow a leading bare key, e.g. "data.token"
+			if len(segments) > 0 {
+				return nil, fmt.Errorf("invalid json path %q", path)
+			}
+			p = "." + p
+		}
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid json path %q", path)
+	}
+	return segments, nil
+}
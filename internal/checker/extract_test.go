@@ -0,0 +1,51 @@
+package checker
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+func TestExtract(t *testing.T) {
+	header := http.Header{"X-Request-Id": {"req-42"}}
+	body := []byte(`{"data":{"token":"abc","items":[{"id":7},{"id":9}],"expires":null},"ok":true}`)
+	html := []byte(`<input name="csrf" value="f00d"> build 1.4.2`)
+
+	tests := []struct {
+		name    string
+		e       domain.Extraction
+		body    []byte
+		want    string
+		wantErr bool
+	}{
+		{"json string", domain.Extraction{JSONPath: "$.data.token"}, body, "abc", false},
+		{"json bracket notation", domain.Extraction{JSONPath: "$['data']['token']"}, body, "abc", false},
+		{"json bare key", domain.Extraction{JSONPath: "data.token"}, body, "abc", false},
+		{"json array index", domain.Extraction{JSONPath: "$.data.items[1].id"}, body, "9", false},
+		{"json negative index", domain.Extraction{JSONPath: "$.data.items[-1].id"}, body, "9", false},
+		{"json object", domain.Extraction{JSONPath: "$.data.items[0]"}, body, `{"id":7}`, false},
+		{"json bool", domain.Extraction{JSONPath: "$.ok"}, body, "true", false},
+		{"json missing key", domain.Extraction{JSONPath: "$.data.session"}, body, "", true},
+		{"json index out of range", domain.Extraction{JSONPath: "$.data.items[2]"}, body, "", true},
+		{"json null", domain.Extraction{JSONPath: "$.data.expires"}, body, "", true},
+		{"json invalid body", domain.Extraction{JSONPath: "$.data"}, html, "", true},
+		{"header", domain.Extraction{Header: "x-request-id"}, nil, "req-42", false},
+		{"missing header", domain.Extraction{Header: "X-Trace-Id"}, nil, "", true},
+		{"regex capture group", domain.Extraction{Regex: `value="([^"]+)"`}, html, "f00d", false},
+		{"regex whole match", domain.Extraction{Regex: `\d+\.\d+\.\d+`}, html, "1.4.2", false},
+		{"regex without match", domain.Extraction{Regex: `token=(\w+)`}, html, "", true},
+		{"invalid regex", domain.Extraction{Regex: `(`}, html, "", true},
+		{"no source", domain.Extraction{Var: "token"}, body, "", true},
+	}
+	for _, tt := range tests {
+		got, err := extract(tt.e, header, tt.body)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
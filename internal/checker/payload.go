@@ -0,0 +1,64 @@
+package checker
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// payload holds the body fields shared by targets and transaction steps. At
+// most one of them is set; config validation enforces this.
+type payload struct {
+	body     string
+	bodyFile string
+	json     map[string]any
+	form     map[string]string
+}
+
+func targetPayload(t domain.Target) payload {
+	return payload{body: t.Body, bodyFile: t.BodyFile, json: t.JSON, form: t.Form}
+}
+
+func stepPayload(s domain.Step) payload {
+	return payload{body: s.Body, bodyFile: s.BodyFile, json: s.JSON, form: s.Form}
+}
+
+// build returns the request body and its content type.
+func (p payload) build() ([]byte, string, error) {
+	switch {
+	case p.body != "":
+		return []byte(p.body), "text/plain; charset=utf-8", nil
+
+	case p.bodyFile != "":
+		body, err := os.ReadFile(p.bodyFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("reading body_file: %w", err)
+		}
+		contentType := mime.TypeByExtension(filepath.Ext(p.bodyFile))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		return body, contentType, nil
+
+	case p.json != nil:
+		body, err := json.Marshal(p.json)
+		if err != nil {
+			return nil, "", fmt.Errorf("encoding json body: %w", err)
+		}
+		return body, "application/json", nil
+
+	case p.form != nil:
+		values := url.Values{}
+		for k, v := range p.form {
+			values.Set(k, v)
+		}
+		return []byte(values.Encode()), "application/x-www-form-urlencoded", nil
+	}
+
+	return nil, "", nil
+}
@@ -0,0 +1,57 @@
+package checker
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/raha-io/joghd/internal/domain"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// resolveProxy returns the proxy to use for rawURL, or nil for a direct
+// connection. Without a configured URL the HTTP_PROXY family of environment
+// variables applies. As with those variables, loopback hosts are never
+// proxied.
+func resolveProxy(p domain.Proxy, rawURL string) (*url.URL, error) {
+	if p.URL == domain.ProxyDirect {
+		return nil, nil
+	}
+
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := httpproxy.FromEnvironment()
+	if p.URL != "" {
+		cfg.HTTPProxy, cfg.HTTPSProxy = p.URL, p.URL
+	}
+	if p.NoProxy != nil {
+		cfg.NoProxy = strings.Join(p.NoProxy, ",")
+	}
+
+	proxy, err := cfg.ProxyFunc()(target)
+	if err != nil || proxy == nil {
+		return nil, err
+	}
+
+	if p.URL != "" && p.Username != "" {
+		password, err := resolveSecret(p.Password)
+		if err != nil {
+			return nil, fmt.Errorf("password: %w", err)
+		}
+		proxy.User = url.UserPassword(p.Username, password)
+	}
+	return proxy, nil
+}
+
+// proxyName returns the proxy URL without credentials, for results and logs.
+func proxyName(proxy *url.URL) string {
+	if proxy == nil {
+		return ""
+	}
+	u := *proxy
+	u.User = nil
+	return u.String()
+}
@@ -0,0 +1,157 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// probeSteps runs a transaction target's steps in order as a single check.
+// Variables extracted from a step's response are available to later steps.
+// The transaction stops at the first failing step, which is recorded in
+// result.FailedStep.
+func (c *checker) probeSteps(ctx context.Context, target domain.Target, result *domain.CheckResult) {
+	result.Steps = make([]domain.StepResult, 0, len(target.Steps))
+	result.FailedStep = ""
+	result.Latency = 0
+	result.Timing = domain.Timing{}
+	result.Proxy = ""
+	result.Error = nil
+
+	vars := make(map[string]string)
+
+	for i, step := range target.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", i+1)
+		}
+
+		sr := c.runStep(ctx, target, step, vars)
+		sr.Name = name
+
+		result.Steps = append(result.Steps, sr)
+		result.Latency += sr.Latency
+		result.ActualStatus = sr.ActualStatus
+		if sr.Proxy != "" {
+			result.Proxy = sr.Proxy
+		}
+
+		if sr.Error != nil {
+			result.FailedStep = name
+			result.Error = fmt.Errorf("step %q: %w", name, sr.Error)
+			return
+		}
+	}
+
+	result.Error = target.MaxLatency.Check(result.Latency, domain.Timing{})
+}
+
+// runStep renders, executes and verifies a single step, storing extracted
+// variables in vars.
+func (c *checker) runStep(ctx context.Context, target domain.Target, step domain.Step, vars map[string]string) domain.StepResult {
+	var sr domain.StepResult
+
+	req, err := newStepRequest(target, step, vars)
+	if err != nil {
+		sr.Error = err
+		return sr
+	}
+	sr.URL = req.URL
+	if err := c.auth.apply(ctx, target.Auth, &req); err != nil {
+		sr.Error = err
+		return sr
+	}
+
+	resp, err := c.httpClient.Execute(ctx, req)
+	if resp.StatusCode == http.StatusUnauthorized {
+		c.auth.invalidate(target.Auth)
+	}
+	sr.ActualStatus = resp.StatusCode
+	sr.Latency = resp.Latency
+	sr.Timing = resp.Timing
+	sr.Proxy = resp.Proxy
+
+	if err := verify(resp, err, step.ExpectedStatus, step.BodyContains, step.MaxLatency); err != nil {
+		sr.Error = err
+		return sr
+	}
+
+	for _, e := range step.Extract {
+		v, err := extract(e, resp.Header, resp.Body)
+		if err != nil {
+			sr.Error = fmt.Errorf("extracting %s: %w", e.Var, err)
+			return sr
+		}
+		vars[e.Var] = v
+	}
+
+	return sr
+}
+
+// newStepRequest builds the HTTP probe for a step, expanding variable
+// references in its URL, headers and payload.
+func newStepRequest(target domain.Target, step domain.Step, vars map[string]string) (Request, error) {
+	rawURL, err := render(step.URL, vars)
+	if err != nil {
+		return Request{}, err
+	}
+	if target.URL != "" {
+		base, err := url.Parse(target.URL)
+		if err != nil {
+			return Request{}, fmt.Errorf("parsing target url: %w", err)
+		}
+		ref, err := url.Parse(rawURL)
+		if err != nil {
+			return Request{}, fmt.Errorf("parsing step url: %w", err)
+		}
+		rawURL = base.ResolveReference(ref).String()
+	}
+
+	headers := maps.Clone(target.Headers)
+	if headers == nil {
+		headers = make(map[string]string, len(step.Headers))
+	}
+	maps.Copy(headers, step.Headers)
+	if headers, err = renderMap(headers, vars); err != nil {
+		return Request{}, err
+	}
+
+	p := stepPayload(step)
+	if p.body, err = render(p.body, vars); err != nil {
+		return Request{}, err
+	}
+	if p.form, err = renderMap(p.form, vars); err != nil {
+		return Request{}, err
+	}
+	if p.json != nil {
+		rendered, err := renderValue(p.json, vars)
+		if err != nil {
+			return Request{}, err
+		}
+		p.json = rendered.(map[string]any)
+	}
+
+	body, contentType, err := p.build()
+	if err != nil {
+		return Request{}, err
+	}
+
+	return Request{
+		Method:      step.Method,
+		URL:         rawURL,
+		Headers:     headers,
+		Timeout:     target.Timeout,
+		Body:        body,
+		ContentType: contentType,
+		KeepAlive:   keepAlive(target),
+		TLS:         target.TLS,
+		Proxy:       target.Proxy,
+
+		FollowRedirects: followRedirects(target),
+		MaxRedirects:    maxRedirects(target),
+	}, nil
+}
@@ -0,0 +1,201 @@
+package checker_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/checker"
+	"github.com/raha-io/joghd/internal/config"
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/fake"
+)
+
+const baseURL = "https://api.example.com"
+
+// transaction logs in, reads the user's profile with the session token and
+// fetches the user's avatar by the ID found in the profile.
+func transaction(url string) domain.Target {
+	return domain.Target{
+		Name:    "Login flow",
+		URL:     url,
+		Timeout: 5 * time.Second,
+		Steps: []domain.Step{
+			{
+				Name:           "login",
+				URL:            "/login",
+				Method:         "POST",
+				ExpectedStatus: 200,
+				JSON:           map[string]any{"user": "probe"},
+				Extract: []domain.Extraction{
+					{Var: "token", JSONPath: "$.token"},
+					{Var: "session", Header: "X-Session"},
+				},
+			},
+			{
+				Name:           "profile",
+				URL:            "/me",
+				Method:         "GET",
+				ExpectedStatus: 200,
+				Headers:        map[string]string{"Authorization": "Bearer {{.token}}", "X-Session": "{{.session}}"},
+				Extract:        []domain.Extraction{{Var: "id", Regex: `"id":\s*(\d+)`}},
+			},
+			{
+				Name:           "avatar",
+				URL:            "/users/{{.id}}/avatar",
+				Method:         "GET",
+				ExpectedStatus: 200,
+			},
+		},
+	}
+}
+
+func TestTransactionSteps(t *testing.T) {
+	login := fake.Step{
+		Status: 200,
+		Header: http.Header{"X-Session": {"s-1"}},
+		Body:   []byte(`{"token":"abc"}`),
+	}
+	profile := fake.Step{Status: 200, Body: []byte(`{"id": 42, "name": "probe"}`)}
+
+	tests := []struct {
+		name       string
+		login      fake.Step
+		profile    fake.Step
+		wantFailed string
+		wantErr    string
+		wantURLs   []string
+	}{
+		{
+			name:     "all steps pass",
+			login:    login,
+			profile:  profile,
+			wantURLs: []string{"/login", "/me", "/users/42/avatar"},
+		},
+		{
+			name:       "failing middle step",
+			login:      login,
+			profile:    fake.Status(500),
+			wantFailed: "profile",
+			wantErr:    "status",
+			wantURLs:   []string{"/login", "/me"},
+		},
+		{
+			name:       "value missing from the response",
+			login:      fake.Step{Status: 200, Header: login.Header, Body: []byte(`{"error":"locked"}`)},
+			profile:    profile,
+			wantFailed: "login",
+			wantErr:    "extracting token",
+			wantURLs:   []string{"/login"},
+		},
+		{
+			name:       "regex without a match",
+			login:      login,
+			profile:    fake.Status(200),
+			wantFailed: "profile",
+			wantErr:    "extracting id",
+			wantURLs:   []string{"/login", "/me"},
+		},
+	}
+	for _, tt := range tests {
+		prober := fake.NewProber(nil).
+			Script(baseURL+"/login", tt.login).
+			Script(baseURL+"/me", tt.profile).
+			Script(baseURL+"/users/42/avatar", fake.Status(200))
+		chk := checker.New(checker.WithHTTPClient(prober), checker.WithRetryConfig(config.RetryConfig{MaxAttempts: 1}))
+
+		result := chk.Check(context.Background(), transaction(baseURL))
+
+		if result.Success != (tt.wantFailed == "") || result.FailedStep != tt.wantFailed {
+			t.Errorf("%s: success=%t failed step %q, want failed step %q: %v",
+				tt.name, result.Success, result.FailedStep, tt.wantFailed, result.Error)
+		}
+		if tt.wantErr != "" && (result.Error == nil || !strings.Contains(result.Error.Error(), tt.wantErr)) {
+			t.Errorf("%s: error = %v, want it to mention %q", tt.name, result.Error, tt.wantErr)
+		}
+
+		reqs := prober.Requests()
+		if len(reqs) != len(tt.wantURLs) {
+			t.Errorf("%s: sent %d requests, want %d", tt.name, len(reqs), len(tt.wantURLs))
+			continue
+		}
+		for i, req := range reqs {
+			if want := baseURL + tt.wantURLs[i]; req.URL != want {
+				t.Errorf("%s: request %d went to %s, want %s", tt.name, i+1, req.URL, want)
+			}
+		}
+		if len(reqs) > 1 {
+			if got := reqs[1].Headers["Authorization"]; got != "Bearer abc" {
+				t.Errorf("%s: profile Authorization = %q, want the extracted token", tt.name, got)
+			}
+			if got := reqs[1].Headers["X-Session"]; got != "s-1" {
+				t.Errorf("%s: profile X-Session = %q, want the extracted header", tt.name, got)
+			}
+		}
+	}
+}
+
+func TestTransactionMissingVariable(t *testing.T) {
+	prober := fake.NewProber(nil).Script(baseURL+"/login", fake.Status(200))
+	chk := checker.New(checker.WithHTTPClient(prober), checker.WithRetryConfig(config.RetryConfig{MaxAttempts: 1}))
+
+	target := transaction(baseURL)
+	target.Steps[0].Extract = nil
+
+	result := chk.Check(context.Background(), target)
+	if result.Success || result.FailedStep != "profile" {
+		t.Fatalf("success=%t failed step %q, want the profile step to fail", result.Success, result.FailedStep)
+	}
+	if !strings.Contains(result.Error.Error(), "token") {
+		t.Errorf("error = %v, want it to name the missing variable", result.Error)
+	}
+	if got := len(prober.Requests()); got != 1 {
+		t.Errorf("sent %d requests, want only the login request", got)
+	}
+}
+
+func TestTransactionAgainstServer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/login":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["user"] != "probe" {
+				http.Error(w, "bad login", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("X-Session", "s-1")
+			w.Write([]byte(`{"token":"abc"}`))
+		case r.URL.Path == "/me":
+			if r.Header.Get("Authorization") != "Bearer abc" || r.Header.Get("X-Session") != "s-1" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"id": 42}`))
+		case r.URL.Path == "/users/42/avatar":
+			w.Write([]byte("png"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	chk := checker.New(
+		checker.WithHTTPClient(checker.NewRestyClient(config.Default().HTTP)),
+		checker.WithRetryConfig(config.RetryConfig{MaxAttempts: 1}),
+	)
+
+	result := chk.Check(context.Background(), transaction(srv.URL))
+	if !result.Success {
+		t.Fatalf("transaction failed at %q: %v", result.FailedStep, result.Error)
+	}
+	if len(result.Steps) != 3 {
+		t.Fatalf("got %d step results, want 3", len(result.Steps))
+	}
+	if got, want := result.Steps[2].URL, srv.URL+"/users/42/avatar"; got != want {
+		t.Errorf("avatar step URL = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,71 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// render expands {{.var}} references in s using vars. Unknown variables are an
+// error rather than an empty string, so a failed extraction surfaces clearly.
+func render(s string, vars map[string]string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	tmpl, err := template.New("").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("parsing template %q: %w", s, err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("rendering template %q: %w", s, err)
+	}
+	return b.String(), nil
+}
+
+func renderMap(m map[string]string, vars map[string]string) (map[string]string, error) {
+	if m == nil {
+		return nil, nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		r, err := render(v, vars)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = r
+	}
+	return out, nil
+}
+
+// renderValue expands templates in every string of a decoded TOML/JSON value.
+func renderValue(v any, vars map[string]string) (any, error) {
+	switch v := v.(type) {
+	case string:
+		return render(v, vars)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			r, err := renderValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = r
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			r, err := renderValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,145 @@
+package checker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// tlsVersions maps configured minimum versions to crypto/tls constants.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newTLSConfig builds the client TLS configuration for settings. The CA bundle
+// and client certificate are loaded lazily on each handshake and reloaded when
+// their files change, so certificate rotation needs no restart.
+func newTLSConfig(settings domain.TLS) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         settings.ServerName,
+		InsecureSkipVerify: settings.InsecureSkipVerify,
+	}
+
+	if settings.MinVersion != "" {
+		v, ok := tlsVersions[settings.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls min_version %q", settings.MinVersion)
+		}
+		cfg.MinVersion = v
+	}
+
+	files := &tlsFiles{settings: settings}
+
+	if settings.CertFile != "" {
+		cfg.GetClientCertificate = files.clientCertificate
+	}
+
+	// A custom CA needs verification against a pool that may change between
+	// handshakes, which tls.Config.RootCAs cannot express.
+	if settings.CAFile != "" && !settings.InsecureSkipVerify {
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = files.verifyConnection
+	}
+
+	return cfg, nil
+}
+
+// tlsFiles caches the certificates read from disk, keyed by modification time.
+type tlsFiles struct {
+	settings domain.TLS
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	certMod  time.Time
+	keyMod   time.Time
+	roots    *x509.CertPool
+	rootsMod time.Time
+}
+
+func (f *tlsFiles) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	certMod, err := modTime(f.settings.CertFile)
+	if err != nil {
+		return nil, err
+	}
+	keyMod, err := modTime(f.settings.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.cert == nil || !certMod.Equal(f.certMod) || !keyMod.Equal(f.keyMod) {
+		cert, err := tls.LoadX509KeyPair(f.settings.CertFile, f.settings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		f.cert, f.certMod, f.keyMod = &cert, certMod, keyMod
+	}
+	return f.cert, nil
+}
+
+func (f *tlsFiles) rootCAs() (*x509.CertPool, error) {
+	mod, err := modTime(f.settings.CAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.roots == nil || !mod.Equal(f.rootsMod) {
+		pem, err := os.ReadFile(f.settings.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading ca bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", f.settings.CAFile)
+		}
+		f.roots, f.rootsMod = pool, mod
+	}
+	return f.roots, nil
+}
+
+// verifyConnection performs the chain and hostname verification that
+// crypto/tls would do, against the configured CA bundle.
+func (f *tlsFiles) verifyConnection(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server presented no certificates")
+	}
+
+	roots, err := f.rootCAs()
+	if err != nil {
+		return err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err = cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+func modTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
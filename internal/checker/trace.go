@@ -0,0 +1,92 @@
+package checker
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// tracer records request phase timestamps via net/http/httptrace. Hooks may
+// fire from transport goroutines, so access is synchronised.
+type tracer struct {
+	mu sync.Mutex
+
+	dnsStart, dnsDone   time.Time
+	connStart, connDone time.Time
+	tlsStart, tlsDone   time.Time
+	wroteRequest        time.Time
+	firstByte           time.Time
+	gotConn             bool
+	reused              bool
+}
+
+func (t *tracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		ConnectStart: func(string, string) {
+			// Dialing may race several addresses; keep the first start
+			t.mu.Lock()
+			if t.connStart.IsZero() {
+				t.connStart = time.Now()
+			}
+			t.mu.Unlock()
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				t.mark(&t.connDone)
+			}
+		},
+		TLSHandshakeStart: func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.mark(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.gotConn = true
+			t.reused = info.Reused
+			t.mu.Unlock()
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.mark(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+	}
+}
+
+func (t *tracer) mark(at *time.Time) {
+	t.mu.Lock()
+	*at = time.Now()
+	t.mu.Unlock()
+}
+
+// connected reports whether a connection to the target was obtained.
+func (t *tracer) connected() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.gotConn
+}
+
+// timing returns the phase durations of a request whose body was fully read at end.
+func (t *tracer) timing(end time.Time) domain.Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timing := domain.Timing{
+		DNSLookup:       between(t.dnsStart, t.dnsDone),
+		Connect:         between(t.connStart, t.connDone),
+		TLSHandshake:    between(t.tlsStart, t.tlsDone),
+		TimeToFirstByte: between(t.wroteRequest, t.firstByte),
+		ConnReused:      t.reused,
+	}
+	if !t.firstByte.IsZero() {
+		timing.ContentTransfer = between(t.firstByte, end)
+	}
+	return timing
+}
+
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
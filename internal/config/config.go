@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -15,18 +16,24 @@ import (
 
 // Config holds all application configuration.
 type Config struct {
-	App      AppConfig       `koanf:"app"`
-	HTTP     HTTPConfig      `koanf:"http"`
-	Retry    RetryConfig     `koanf:"retry"`
-	Alerters AlertersConfig  `koanf:"alerters"`
-	Targets  []domain.Target `koanf:"targets"`
+	App         AppConfig        `koanf:"app"`
+	HTTP        HTTPConfig       `koanf:"http"`
+	Retry       RetryConfig      `koanf:"retry"`
+	API         APIConfig        `koanf:"api"`
+	Incidents   IncidentsConfig  `koanf:"incidents"`
+	Alerters    AlertersConfig   `koanf:"alerters"`
+	Maintenance []domain.Silence `koanf:"maintenance"`
+	Targets     []domain.Target  `koanf:"targets"`
 }
 
 // AppConfig holds application-level settings.
 type AppConfig struct {
-	Mode        string `koanf:"mode"`
-	LogLevel    string `koanf:"log_level"`
-	Concurrency int    `koanf:"concurrency"`
+	Mode        string        `koanf:"mode"`
+	LogLevel    string        `koanf:"log_level"`
+	Concurrency int           `koanf:"concurrency"`
+	WatchConfig bool          `koanf:"watch_config"`
+	StartSpread time.Duration `koanf:"start_spread"`
+	Jitter      float64       `koanf:"jitter"`
 }
 
 // HTTPConfig holds HTTP client settings.
@@ -34,6 +41,11 @@ type HTTPConfig struct {
 	Timeout             time.Duration `koanf:"timeout"`
 	UserAgent           string        `koanf:"user_agent"`
 	SkipTLSVerification bool          `koanf:"skip_tls_verification"`
+	KeepAlive           bool          `koanf:"keep_alive"`
+	MaxIdleConnsPerHost int           `koanf:"max_idle_conns_per_host"`
+	IdleConnTimeout     time.Duration `koanf:"idle_conn_timeout"`
+	TLS                 domain.TLS    `koanf:"tls"`
+	Proxy               domain.Proxy  `koanf:"proxy"`
 }
 
 // RetryConfig holds retry behavior settings.
@@ -44,20 +56,138 @@ type RetryConfig struct {
 	Multiplier  float64       `koanf:"multiplier"`
 }
 
+// APIConfig holds settings of the HTTP API served in continuous mode. If Token
+// is set, requests must carry it as a bearer token.
+type APIConfig struct {
+	Enabled bool   `koanf:"enabled"`
+	Listen  string `koanf:"listen"`
+	Token   string `koanf:"token" redact:"true"`
+}
+
+// IncidentsConfig holds settings of the incidents tracked in continuous mode.
+// They are saved to StateFile, if set, and resolved ones are kept for
+// Retention, at most the 500 most recent (zero only applies that limit).
+type IncidentsConfig struct {
+	StateFile string        `koanf:"state_file"`
+	Retention time.Duration `koanf:"retention"`
+}
+
 // AlertersConfig holds alerter configurations.
 type AlertersConfig struct {
 	Telegram TelegramConfig `koanf:"telegram"`
 }
 
-// TelegramConfig holds Telegram alerter settings.
+// TelegramConfig holds Telegram alerter settings. With Commands enabled, the
+// bot also answers commands in continuous mode, from chats in AllowedChatIDs
+// (default: ChatID) and, if set, only from users in AllowedUserIDs. APIURL
+// points at the Bot API, e.g. a local stand-in for testing.
+//
+// In forum chats, alerts go to the topic ThreadID, or to the first of Threads
+// matching the target.
 type TelegramConfig struct {
-	Enabled  bool   `koanf:"enabled"`
-	BotToken string `koanf:"bot_token"`
-	ChatID   string `koanf:"chat_id"`
+	Enabled        bool              `koanf:"enabled"`
+	BotToken       string            `koanf:"bot_token" redact:"true"`
+	ChatID         string            `koanf:"chat_id"`
+	APIURL         string            `koanf:"api_url"`
+	ThreadID       int64             `koanf:"thread_id"`
+	Threads        []TelegramThread  `koanf:"threads"`
+	Commands       bool              `koanf:"commands"`
+	AllowedChatIDs []string          `koanf:"allowed_chat_ids"`
+	AllowedUserIDs []int64           `koanf:"allowed_user_ids"`
+	Templates      TelegramTemplates `koanf:"templates"`
+}
+
+// TelegramTemplates override the layout of alert messages per alert type.
+// Each is an html/template executed with the domain.Alert, producing
+// Telegram's parse_mode HTML; empty ones use the built-in layout.
+type TelegramTemplates struct {
+	Failure        string `koanf:"failure"`
+	Recovery       string `koanf:"recovery"`
+	BurnRate       string `koanf:"burn_rate"`
+	SilenceSummary string `koanf:"silence_summary"`
+}
+
+// ByType returns the configured templates keyed by alert type.
+func (t TelegramTemplates) ByType() map[domain.AlertType]string {
+	out := make(map[domain.AlertType]string)
+	for alertType, text := range map[domain.AlertType]string{
+		domain.AlertTypeFailure:        t.Failure,
+		domain.AlertTypeRecovery:       t.Recovery,
+		domain.AlertTypeBurnRate:       t.BurnRate,
+		domain.AlertTypeSilenceSummary: t.SilenceSummary,
+	} {
+		if text != "" {
+			out[alertType] = text
+		}
+	}
+	return out
+}
+
+// TelegramThread routes alerts for a group of targets, matched by name (glob
+// patterns) and/or labels, to a forum topic.
+type TelegramThread struct {
+	ThreadID int64             `koanf:"thread_id"`
+	Targets  []string          `koanf:"targets"`
+	Labels   map[string]string `koanf:"labels"`
+}
+
+// Override changes a loaded configuration before it is validated, e.g. to
+// apply command-line flags.
+type Override func(*Config)
+
+// WithMode overrides app.mode, unless mode is empty.
+func WithMode(mode string) Override {
+	return func(cfg *Config) {
+		if mode != "" {
+			cfg.App.Mode = mode
+		}
+	}
+}
+
+// Load loads configuration from file and environment variables, applies the
+// overrides and validates the result.
+func Load(configPath string, overrides ...Override) (*Config, error) {
+	k, err := load(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := unmarshal(k)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range overrides {
+		o(cfg)
+	}
+
+	if err := validate(cfg).Err(); err != nil {
+		return nil, fmt.Errorf("validating config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Inspect loads configuration like Load, but instead of failing on the first
+// invalid setting it reports every problem found, including keys that do not
+// map to any setting. The returned error is only set if the configuration
+// could not be read at all.
+func Inspect(configPath string) (*Config, Diagnostics, error) {
+	k, err := load(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg, err := unmarshal(k)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	diags := unknownKeys(k.Raw(), reflect.TypeOf(Config{}), "")
+	diags = append(diags, validate(cfg)...)
+	return cfg, diags, nil
 }
 
-// Load loads configuration from file and environment variables.
-func Load(configPath string) (*Config, error) {
+func load(configPath string) (*koanf.Koanf, error) {
 	k := koanf.New(".")
 
 	// Load defaults from struct
@@ -76,62 +206,73 @@ func Load(configPath string) (*Config, error) {
 	if err := k.Load(env.Provider(".", env.Opt{
 		Prefix: "JOGHD_",
 		TransformFunc: func(key, value string) (string, any) {
-			return strings.ReplaceAll(strings.ToLower(key), "_", "."), value
+			return envKey(strings.TrimPrefix(key, "JOGHD_")), value
 		},
 	}), nil); err != nil {
 		return nil, fmt.Errorf("loading env config: %w", err)
 	}
 
+	return k, nil
+}
+
+func unmarshal(k *koanf.Koanf) (*Config, error) {
 	var cfg Config
 	if err := k.Unmarshal("", &cfg); err != nil {
 		return nil, fmt.Errorf("unmarshaling config: %w", err)
 	}
 
-	if err := validate(&cfg); err != nil {
-		return nil, fmt.Errorf("validating config: %w", err)
-	}
-
 	// Apply defaults to targets
 	for i := range cfg.Targets {
+		if cfg.Targets[i].Type == "" {
+			cfg.Targets[i].Type = domain.TargetTypeHTTP
+		}
+		if cfg.Targets[i].Push() && cfg.Targets[i].Grace == 0 {
+			cfg.Targets[i].Grace = time.Minute
+		}
 		if cfg.Targets[i].Method == "" {
 			cfg.Targets[i].Method = "GET"
 		}
+		cfg.Targets[i].Method = strings.ToUpper(cfg.Targets[i].Method)
 		if cfg.Targets[i].Timeout == 0 {
 			cfg.Targets[i].Timeout = cfg.HTTP.Timeout
 		}
-		if cfg.Targets[i].Interval == 0 {
+		if cfg.Targets[i].KeepAlive == nil {
+			keepAlive := cfg.HTTP.KeepAlive
+			cfg.Targets[i].KeepAlive = &keepAlive
+		}
+		cfg.Targets[i].TLS = cfg.Targets[i].TLS.Merge(cfg.HTTP.TLS)
+		cfg.Targets[i].Proxy = cfg.Targets[i].Proxy.Merge(cfg.HTTP.Proxy)
+		if cfg.Targets[i].FollowRedirects == nil {
+			follow := true
+			cfg.Targets[i].FollowRedirects = &follow
+		}
+		if cfg.Targets[i].MaxRedirects == nil {
+			maxRedirects := domain.DefaultMaxRedirects
+			cfg.Targets[i].MaxRedirects = &maxRedirects
+		}
+		if cfg.Targets[i].Interval == 0 && cfg.Targets[i].Schedule == "" {
 			cfg.Targets[i].Interval = 30 * time.Second
 		}
+		if cfg.Targets[i].FailureThreshold == 0 {
+			cfg.Targets[i].FailureThreshold = 1
+		}
 		if cfg.Targets[i].ExpectedStatus == 0 {
 			cfg.Targets[i].ExpectedStatus = 200
 		}
-	}
-
-	return &cfg, nil
-}
-
-func validate(cfg *Config) error {
-	if cfg.App.Mode != "oneshot" && cfg.App.Mode != "continuous" {
-		return fmt.Errorf("invalid app.mode: %s (must be 'oneshot' or 'continuous')", cfg.App.Mode)
-	}
-
-	if cfg.Alerters.Telegram.Enabled {
-		if cfg.Alerters.Telegram.BotToken == "" {
-			return fmt.Errorf("telegram.bot_token is required when telegram is enabled")
-		}
-		if cfg.Alerters.Telegram.ChatID == "" {
-			return fmt.Errorf("telegram.chat_id is required when telegram is enabled")
+		for j := range cfg.Targets[i].Steps {
+			step := &cfg.Targets[i].Steps[j]
+			if step.Method == "" {
+				step.Method = "GET"
+			}
+			step.Method = strings.ToUpper(step.Method)
+			if step.ExpectedStatus == 0 {
+				step.ExpectedStatus = 200
+			}
 		}
-	}
-
-	for i, t := range cfg.Targets {
-		if t.URL == "" {
-			return fmt.Errorf("target[%d]: url is required", i)
-		}
-		if t.Name == "" {
-			return fmt.Errorf("target[%d]: name is required", i)
+		if cfg.Targets[i].SLO.Enabled() && cfg.Targets[i].SLO.Window == 0 {
+			cfg.Targets[i].SLO.Window = 30 * 24 * time.Hour
 		}
 	}
 
-	return nil
+	return &cfg, nil
 }
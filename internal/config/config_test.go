@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// writeConfig writes a TOML config to a temporary file and returns its path.
+func writeConfig(t *testing.T, toml string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(toml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadMaxRedirects(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+[[targets]]
+name = "Default"
+url = "https://example.com"
+
+[[targets]]
+name = "No redirects"
+url = "https://example.com"
+follow_redirects = true
+max_redirects = 0
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	for i, want := range []int{domain.DefaultMaxRedirects, 0} {
+		got := cfg.Targets[i].MaxRedirects
+		if got == nil || *got != want {
+			t.Errorf("%s: max_redirects = %v, want %d", cfg.Targets[i].Name, got, want)
+		}
+	}
+}
+
+func TestLoadKeepsIncidentsInMemoryByDefault(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+[[targets]]
+name = "API"
+url = "https://example.com"
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Incidents.StateFile != "" {
+		t.Errorf("incidents.state_file = %q, want \"\" unless set", cfg.Incidents.StateFile)
+	}
+}
+
+// invalidConfig has one problem per target, so every diagnostic can be told
+// apart by its path.
+const invalidConfig = `
+[alerters.telegram]
+enabled = false
+
+[[targets]]
+name = "API"
+url = "https://api.example.com"
+intervall = "1m"
+
+[[targets]]
+name = "API"
+url = "https://api2.example.com"
+
+[[targets]]
+name = "FTP"
+url = "ftp://files.example.com"
+
+[[targets]]
+name = "Fetch"
+url = "https://example.com"
+method = "fetch"
+
+[[targets]]
+name = "Slow"
+url = "https://slow.example.com"
+timeout = "20s"
+interval = "30s"
+
+[[targets]]
+name = "Gateway"
+url = "https://gw.example.com"
+depends_on = ["Auth"]
+
+[[targets]]
+name = "Auth"
+url = "https://auth.example.com"
+depends_on = ["Gateway"]
+`
+
+func TestInspectReportsEveryProblem(t *testing.T) {
+	_, diags, err := Inspect(writeConfig(t, invalidConfig))
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+
+	want := Diagnostics{
+		{LevelError, "targets[0].intervall", "unknown key"},
+		{LevelError, "targets[1].name", `duplicate name "API" (also used by targets[0])`},
+		{LevelError, "targets[2].url", `unsupported scheme "ftp" (must be http or https)`},
+		{LevelError, "targets[3].method", `unsupported method "FETCH" (must be one of GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS)`},
+		{LevelWarning, "targets[4].interval", "30s is shorter than timeout × retries (1m0s); checks may overlap"},
+		{LevelError, "targets[6].depends_on", "dependency cycle: Auth → Gateway → Auth"},
+	}
+	if len(diags) != len(want) {
+		t.Errorf("got %d diagnostics, want %d:\n%v", len(diags), len(want), diags)
+	}
+	for i := range min(len(diags), len(want)) {
+		if diags[i] != want[i] {
+			t.Errorf("diagnostic %d = %s %q, want %s %q", i, diags[i].Level, diags[i], want[i].Level, want[i])
+		}
+	}
+}
+
+func TestLoadReportsEveryError(t *testing.T) {
+	_, err := Load(writeConfig(t, invalidConfig))
+	if err == nil {
+		t.Fatal("Load accepted an invalid config")
+	}
+
+	// Unknown keys and warnings are left to Inspect
+	for _, path := range []string{"targets[1].name", "targets[2].url", "targets[3].method", "targets[6].depends_on"} {
+		if !strings.Contains(err.Error(), path+":") {
+			t.Errorf("error does not report %s: %v", path, err)
+		}
+	}
+	for _, path := range []string{"targets[0].intervall", "targets[4].interval"} {
+		if strings.Contains(err.Error(), path+":") {
+			t.Errorf("error reports %s: %v", path, err)
+		}
+	}
+}
@@ -14,6 +14,9 @@ func Default() Config {
 			Timeout:             10 * time.Second,
 			UserAgent:           "Joghd/1.0",
 			SkipTLSVerification: false,
+			KeepAlive:           true,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     90 * time.Second,
 		},
 		Retry: RetryConfig{
 			MaxAttempts: 3,
@@ -21,9 +24,17 @@ func Default() Config {
 			MaxWait:     10 * time.Second,
 			Multiplier:  2.0,
 		},
+		API: APIConfig{
+			Enabled: false,
+			Listen:  "127.0.0.1:8080",
+		},
+		Incidents: IncidentsConfig{
+			Retention: 30 * 24 * time.Hour,
+		},
 		Alerters: AlertersConfig{
 			Telegram: TelegramConfig{
 				Enabled: false,
+				APIURL:  "https://api.telegram.org",
 			},
 		},
 	}
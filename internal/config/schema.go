@@ -0,0 +1,251 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf/parsers/toml"
+)
+
+// redacted replaces secret values when rendering configuration.
+const redacted = "REDACTED"
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// sensitiveHeaders are substrings of header names whose values are redacted.
+var sensitiveHeaders = []string{"authorization", "cookie", "token", "secret", "key", "password", "signature"}
+
+// fieldByKey returns the struct field tagged with the given koanf key.
+func fieldByKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("koanf") == key {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// envKey maps an environment variable name (without the JOGHD_ prefix) to a
+// config key. Underscores are ambiguous since they both separate sections and
+// appear in key names, so the name is resolved against the Config schema:
+// ALERTERS_TELEGRAM_BOT_TOKEN becomes alerters.telegram.bot_token.
+func envKey(name string) string {
+	segs := strings.Split(strings.ToLower(name), "_")
+	if path, ok := resolveKey(reflect.TypeOf(Config{}), segs); ok {
+		return strings.Join(path, ".")
+	}
+	return strings.Join(segs, ".")
+}
+
+func resolveKey(t reflect.Type, segs []string) ([]string, bool) {
+	if len(segs) == 0 {
+		return nil, true
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return resolveKey(t.Elem(), segs)
+	case reflect.Map:
+		return []string{strings.Join(segs, "_")}, true
+	case reflect.Struct:
+		if t == durationType || t == timeType {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+
+	// Prefer the longest matching key at each level
+	for n := len(segs); n >= 1; n-- {
+		key := strings.Join(segs[:n], "_")
+		f, ok := fieldByKey(t, key)
+		if !ok {
+			continue
+		}
+		rest, ok := resolveKey(f.Type, segs[n:])
+		if ok {
+			return append([]string{key}, rest...), true
+		}
+	}
+	return nil, false
+}
+
+// unknownKeys reports keys in raw that do not map to a field of t.
+func unknownKeys(raw map[string]any, t reflect.Type, path string) Diagnostics {
+	var d Diagnostics
+
+	keys := make([]string, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+
+		f, ok := fieldByKey(t, key)
+		if !ok {
+			d.errorf(keyPath, "unknown key")
+			continue
+		}
+		d = append(d, unknownKeysIn(raw[key], f.Type, keyPath)...)
+	}
+
+	return d
+}
+
+func unknownKeysIn(value any, t reflect.Type, path string) Diagnostics {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t.Kind() == reflect.Struct && t != durationType && t != timeType:
+		if m, ok := value.(map[string]any); ok {
+			return unknownKeys(m, t, path)
+		}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Struct:
+		var d Diagnostics
+		for i, item := range toSlice(value) {
+			if m, ok := item.(map[string]any); ok {
+				d = append(d, unknownKeys(m, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+		return d
+	}
+	return nil
+}
+
+func toSlice(value any) []any {
+	switch v := value.(type) {
+	case []any:
+		return v
+	case []map[string]any:
+		out := make([]any, len(v))
+		for i, m := range v {
+			out[i] = m
+		}
+		return out
+	}
+	return nil
+}
+
+// Render returns the configuration as TOML with secrets redacted.
+func Render(cfg *Config) ([]byte, error) {
+	m, _ := toMap(reflect.ValueOf(*cfg)).(map[string]any)
+	return toml.Parser().Marshal(m)
+}
+
+// toMap converts v to TOML-friendly values keyed by koanf tags. Durations are
+// rendered as strings and fields tagged redact:"true" are masked.
+func toMap(v reflect.Value) any {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == durationType:
+		return v.Interface().(time.Duration).String()
+	case v.Type() == timeType:
+		if t := v.Interface().(time.Time); !t.IsZero() {
+			return t.Format(time.RFC3339)
+		}
+		return nil
+	case v.Kind() == reflect.Struct:
+		out := make(map[string]any)
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			key := f.Tag.Get("koanf")
+			if key == "" || key == "-" || !f.IsExported() {
+				continue
+			}
+			var value any
+			if f.Tag.Get("redact") == "true" {
+				value = redact(v.Field(i))
+			} else {
+				value = toMap(v.Field(i))
+			}
+			if value != nil {
+				out[key] = value
+			}
+		}
+		return out
+	case v.Kind() == reflect.Slice:
+		if v.Len() == 0 {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Struct && v.Type().Elem() != durationType {
+			out := make([]map[string]any, v.Len())
+			for i := range out {
+				out[i], _ = toMap(v.Index(i)).(map[string]any)
+			}
+			return out
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = toMap(v.Index(i))
+		}
+		return out
+	case v.Kind() == reflect.Map:
+		if v.Len() == 0 {
+			return nil
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = toMap(iter.Value())
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+// redact masks a secret value. Maps are treated as headers: only values of
+// sensitive-looking keys are masked.
+func redact(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Map:
+		m, ok := toMap(v).(map[string]any)
+		if !ok {
+			return nil
+		}
+		for k := range m {
+			if isSensitiveHeader(k) {
+				m[k] = redacted
+			}
+		}
+		return m
+	case reflect.String:
+		// Secret references such as env:NAME or file:/path are not secret
+		if s := v.String(); s == "" || strings.HasPrefix(s, "env:") || strings.HasPrefix(s, "file:") {
+			return s
+		}
+		return redacted
+	default:
+		return toMap(v)
+	}
+}
+
+func isSensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitiveHeaders {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
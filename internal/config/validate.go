@@ -0,0 +1,607 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/telegram"
+	"github.com/robfig/cron/v3"
+)
+
+// templateVar matches names usable as {{.name}} in step templates.
+var templateVar = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// pushToken matches valid push target tokens, used in ping URLs.
+var pushToken = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tlsVersions lists the accepted tls.min_version values.
+var tlsVersions = []string{"1.0", "1.1", "1.2", "1.3"}
+
+// proxySchemes lists the accepted proxy url schemes.
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// supportedMethods lists the HTTP methods a target may use.
+var supportedMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// Level is the severity of a configuration diagnostic.
+type Level int
+
+const (
+	LevelError Level = iota
+	LevelWarning
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelError:
+		return "error"
+	case LevelWarning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// Diagnostic is a single configuration problem located by its TOML path.
+type Diagnostic struct {
+	Level   Level
+	Path    string
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	if d.Path == "" {
+		return d.Message
+	}
+	return fmt.Sprintf("%s: %s", d.Path, d.Message)
+}
+
+// Diagnostics is a list of configuration problems.
+type Diagnostics []Diagnostic
+
+// Errors returns the diagnostics at error level.
+func (d Diagnostics) Errors() Diagnostics {
+	return d.filter(LevelError)
+}
+
+// Warnings returns the diagnostics at warning level.
+func (d Diagnostics) Warnings() Diagnostics {
+	return d.filter(LevelWarning)
+}
+
+// Err joins all error-level diagnostics into a single error, or returns nil if
+// there are none.
+func (d Diagnostics) Err() error {
+	var errs []error
+	for _, diag := range d.Errors() {
+		errs = append(errs, errors.New(diag.String()))
+	}
+	return errors.Join(errs...)
+}
+
+func (d Diagnostics) filter(level Level) Diagnostics {
+	var out Diagnostics
+	for _, diag := range d {
+		if diag.Level == level {
+			out = append(out, diag)
+		}
+	}
+	return out
+}
+
+func (d *Diagnostics) errorf(path, format string, args ...any) {
+	*d = append(*d, Diagnostic{Level: LevelError, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *Diagnostics) warnf(path, format string, args ...any) {
+	*d = append(*d, Diagnostic{Level: LevelWarning, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func validate(cfg *Config) Diagnostics {
+	var d Diagnostics
+
+	if cfg.App.Mode != "oneshot" && cfg.App.Mode != "continuous" {
+		d.errorf("app.mode", "invalid mode %q (must be 'oneshot' or 'continuous')", cfg.App.Mode)
+	}
+	if cfg.App.Concurrency < 1 {
+		d.errorf("app.concurrency", "must be at least 1, got %d", cfg.App.Concurrency)
+	}
+	if cfg.App.StartSpread < 0 {
+		d.errorf("app.start_spread", "must not be negative")
+	}
+	if cfg.App.Jitter < 0 || cfg.App.Jitter > 1 {
+		d.errorf("app.jitter", "must be between 0 and 1, got %v", cfg.App.Jitter)
+	} else if cfg.App.Jitter > 0.5 {
+		d.warnf("app.jitter", "%v allows checks up to %.0f%% of the interval early or late", cfg.App.Jitter, cfg.App.Jitter*100)
+	}
+
+	if cfg.HTTP.Timeout <= 0 {
+		d.errorf("http.timeout", "must be positive, got %s", cfg.HTTP.Timeout)
+	}
+	if cfg.HTTP.MaxIdleConnsPerHost < 0 {
+		d.errorf("http.max_idle_conns_per_host", "must not be negative")
+	}
+	if cfg.HTTP.IdleConnTimeout < 0 {
+		d.errorf("http.idle_conn_timeout", "must not be negative")
+	}
+	d.validateTLS("http.tls", cfg.HTTP.TLS)
+	d.validateProxy("http.proxy", cfg.HTTP.Proxy)
+
+	if cfg.Retry.MaxAttempts < 1 {
+		d.errorf("retry.max_attempts", "must be at least 1, got %d", cfg.Retry.MaxAttempts)
+	}
+	if cfg.Retry.Multiplier < 1 {
+		d.warnf("retry.multiplier", "%v shrinks the wait between retries", cfg.Retry.Multiplier)
+	}
+	if cfg.Retry.MaxWait < cfg.Retry.InitialWait {
+		d.warnf("retry.max_wait", "%s is shorter than retry.initial_wait (%s)", cfg.Retry.MaxWait, cfg.Retry.InitialWait)
+	}
+
+	if cfg.Alerters.Telegram.Enabled {
+		if cfg.Alerters.Telegram.BotToken == "" {
+			d.errorf("alerters.telegram.bot_token", "is required when telegram is enabled")
+		}
+		if cfg.Alerters.Telegram.ChatID == "" {
+			d.errorf("alerters.telegram.chat_id", "is required when telegram is enabled")
+		}
+		d.validateURL("alerters.telegram.api_url", cfg.Alerters.Telegram.APIURL)
+		for alertType, text := range cfg.Alerters.Telegram.Templates.ByType() {
+			key := strings.ToLower(alertType.String())
+			if _, err := telegram.ParseTemplate(key, text); err != nil {
+				d.errorf("alerters.telegram.templates."+key, "%v", err)
+			}
+		}
+		if cfg.Alerters.Telegram.ThreadID < 0 {
+			d.errorf("alerters.telegram.thread_id", "must not be negative")
+		}
+		for i, th := range cfg.Alerters.Telegram.Threads {
+			path := fmt.Sprintf("alerters.telegram.threads[%d]", i)
+			if th.ThreadID <= 0 {
+				d.errorf(path+".thread_id", "must be positive")
+			}
+			if len(th.Targets) == 0 && len(th.Labels) == 0 {
+				d.errorf(path, "targets or labels must be set")
+			}
+			if err := domain.ValidatePatterns(th.Targets); err != nil {
+				d.errorf(path+".targets", "%v", err)
+			}
+		}
+	}
+	if tg := cfg.Alerters.Telegram; tg.Commands {
+		switch {
+		case !tg.Enabled:
+			d.errorf("alerters.telegram.commands", "requires telegram to be enabled")
+		case cfg.App.Mode == "oneshot":
+			d.warnf("alerters.telegram.commands", "bot commands are only answered in continuous mode")
+		case len(tg.AllowedUserIDs) == 0:
+			d.warnf("alerters.telegram.allowed_user_ids", "not set, every member of the allowed chats can run commands")
+		}
+	}
+
+	if cfg.API.Enabled {
+		if _, _, err := net.SplitHostPort(cfg.API.Listen); err != nil {
+			d.errorf("api.listen", "invalid address %q: %v", cfg.API.Listen, err)
+		}
+		if cfg.API.Token == "" {
+			d.warnf("api.token", "not set, the API accepts unauthenticated requests")
+		}
+	}
+
+	if cfg.Incidents.Retention < 0 {
+		d.errorf("incidents.retention", "must not be negative")
+	}
+	if file := cfg.Incidents.StateFile; file != "" && cfg.App.Mode == "continuous" {
+		if info, err := os.Stat(filepath.Dir(file)); err != nil || !info.IsDir() {
+			d.errorf("incidents.state_file", "directory of %s does not exist", file)
+		}
+	}
+
+	names := make(map[string]int, len(cfg.Targets))
+	tokens := make(map[string]int)
+	for i, t := range cfg.Targets {
+		path := fmt.Sprintf("targets[%d]", i)
+
+		if t.Name == "" {
+			d.errorf(path+".name", "is required")
+		} else if j, dup := names[t.Name]; dup {
+			d.errorf(path+".name", "duplicate name %q (also used by targets[%d])", t.Name, j)
+		} else {
+			names[t.Name] = i
+		}
+
+		switch t.Type {
+		case domain.TargetTypeHTTP:
+			if t.URL != "" {
+				d.validateURL(path+".url", t.URL)
+			} else if len(t.Steps) == 0 {
+				d.errorf(path+".url", "is required")
+			}
+		case domain.TargetTypePush:
+			d.validatePush(path, cfg, t)
+			if j, dup := tokens[t.Token]; dup && t.Token != "" {
+				d.errorf(path+".token", "already used by targets[%d]", j)
+			}
+			tokens[t.Token] = i
+		default:
+			d.errorf(path+".type", "unknown type %q (must be 'http' or 'push')", t.Type)
+		}
+
+		d.validateMethod(path+".method", t.Method)
+		d.validatePayload(path, t.Method, t.Body, t.BodyFile, t.JSON, t.Form)
+
+		d.validateAuth(path+".auth", t.Auth)
+		// Settings inherited unchanged from http.tls are reported there
+		if t.TLS != cfg.HTTP.TLS {
+			d.validateTLS(path+".tls", t.TLS)
+		}
+		if !reflect.DeepEqual(t.Proxy, cfg.HTTP.Proxy) {
+			d.validateProxy(path+".proxy", t.Proxy)
+		}
+
+		for j, step := range t.Steps {
+			d.validateStep(fmt.Sprintf("%s.steps[%d]", path, j), t, step)
+		}
+
+		if t.ExpectedStatus < 100 || t.ExpectedStatus > 599 {
+			d.errorf(path+".expected_status", "%d is not a valid HTTP status", t.ExpectedStatus)
+		}
+
+		if t.MaxRedirects != nil && *t.MaxRedirects < 0 {
+			d.errorf(path+".max_redirects", "must not be negative")
+		}
+		if t.ExpectedFinalURL != "" {
+			if _, err := url.Parse(t.ExpectedFinalURL); err != nil {
+				d.errorf(path+".expected_final_url", "cannot parse %q: %v", t.ExpectedFinalURL, err)
+			}
+			if t.FollowRedirects != nil && !*t.FollowRedirects {
+				d.warnf(path+".expected_final_url", "redirects are not followed, so the final url is always the target url")
+			}
+			if len(t.Steps) > 0 {
+				d.warnf(path+".expected_final_url", "ignored for transactions")
+			}
+		}
+
+		if t.Timeout < 0 {
+			d.errorf(path+".timeout", "must not be negative")
+		}
+		switch worst := worstCaseCheck(t, cfg.Retry); {
+		case t.Schedule != "":
+			if _, err := cron.ParseStandard(t.Schedule); err != nil {
+				d.errorf(path+".schedule", "%v", err)
+			}
+			if t.Interval != 0 {
+				d.warnf(path+".interval", "ignored since schedule is set")
+			}
+		case t.Interval <= 0:
+			d.errorf(path+".interval", "must be positive, got %s", t.Interval)
+		case t.Interval < worst:
+			d.warnf(path+".interval", "%s is shorter than timeout × retries (%s); checks may overlap", t.Interval, worst)
+		}
+
+		if t.FailureThreshold < 1 {
+			d.errorf(path+".failure_threshold", "must be at least 1, got %d", t.FailureThreshold)
+		}
+		switch worst := worstCaseCheck(t, cfg.Retry); {
+		case t.FailingInterval < 0:
+			d.errorf(path+".failing_interval", "must not be negative")
+		case t.FailingInterval == 0:
+		case t.Schedule == "" && t.FailingInterval >= t.Interval:
+			d.warnf(path+".failing_interval", "%s is not shorter than interval (%s)", t.FailingInterval, t.Interval)
+		case t.FailingInterval < worst:
+			d.warnf(path+".failing_interval", "%s is shorter than timeout × retries (%s); checks may overlap", t.FailingInterval, worst)
+		}
+
+		if t.TimeZone != "" {
+			if _, err := time.LoadLocation(t.TimeZone); err != nil {
+				d.errorf(path+".timezone", "unknown time zone %q", t.TimeZone)
+			}
+		}
+		for j, w := range t.ActiveWindows {
+			if err := w.Validate(); err != nil {
+				d.errorf(fmt.Sprintf("%s.active_windows[%d]", path, j), "%v", err)
+			}
+		}
+
+		if l := t.MaxLatency; l.Total < 0 || l.DNSLookup < 0 || l.Connect < 0 ||
+			l.TLSHandshake < 0 || l.TimeToFirstByte < 0 || l.ContentTransfer < 0 {
+			d.errorf(path+".max_latency", "limits must not be negative")
+		}
+
+		if t.SLO.Objective < 0 || t.SLO.Objective >= 100 {
+			d.errorf(path+".slo.objective", "must be between 0 and 100, got %v", t.SLO.Objective)
+		}
+		if t.SLO.Window < 0 {
+			d.errorf(path+".slo.window", "must not be negative")
+		}
+	}
+
+	for i, t := range cfg.Targets {
+		path := fmt.Sprintf("targets[%d].depends_on", i)
+		for _, dep := range t.DependsOn {
+			switch _, ok := names[dep]; {
+			case dep == t.Name:
+				d.errorf(path, "target depends on itself")
+			case !ok:
+				d.errorf(path, "unknown target %q", dep)
+			}
+		}
+	}
+	if cycle := domain.NewDependencyGraph(cfg.Targets).Cycle(); len(cycle) > 2 {
+		d.errorf(fmt.Sprintf("targets[%d].depends_on", names[cycle[0]]), "dependency cycle: %s", strings.Join(cycle, " → "))
+	}
+
+	silences := make(map[string]bool, len(cfg.Maintenance))
+	for i, m := range cfg.Maintenance {
+		path := fmt.Sprintf("maintenance[%d]", i)
+		switch {
+		case m.Name == "":
+			d.errorf(path+".name", "is required")
+		case silences[m.Name]:
+			d.errorf(path+".name", "duplicate maintenance name %q", m.Name)
+		}
+		silences[m.Name] = true
+
+		if err := m.Validate(); err != nil {
+			d.errorf(path, "%v", err)
+			continue
+		}
+		if m.Expired(time.Now()) {
+			d.warnf(path+".ends", "maintenance ended at %s", m.Ends.Format(time.RFC3339))
+		}
+		if !slices.ContainsFunc(cfg.Targets, m.Matches) {
+			d.warnf(path, "matches no configured target")
+		}
+	}
+
+	return d
+}
+
+func (d *Diagnostics) validatePush(path string, cfg *Config, t domain.Target) {
+	switch {
+	case t.Token == "":
+		d.errorf(path+".token", "is required for push targets")
+	case !pushToken.MatchString(t.Token):
+		d.errorf(path+".token", "may only contain letters, digits, '-' and '_'")
+	case len(t.Token) < 16:
+		d.warnf(path+".token", "short tokens are easy to guess; anyone who knows it can ping the target")
+	}
+	if t.Period <= 0 {
+		d.errorf(path+".period", "must be positive for push targets")
+	}
+	if t.Grace < 0 {
+		d.errorf(path+".grace", "must not be negative")
+	}
+	if t.URL != "" || len(t.Steps) > 0 {
+		d.errorf(path, "push targets are pinged, url and steps must not be set")
+	}
+	if t.Schedule != "" || len(t.ActiveWindows) > 0 || t.FailingInterval > 0 {
+		d.errorf(path, "schedule, active_windows and failing_interval do not apply to push targets")
+	}
+	switch {
+	case cfg.App.Mode == "oneshot":
+		d.warnf(path+".type", "push targets are skipped in oneshot mode")
+	case !cfg.API.Enabled:
+		d.errorf(path+".type", "push targets need the API enabled ([api] enabled = true) to receive pings")
+	}
+}
+
+func (d *Diagnostics) validateURL(path, raw string) {
+	u, err := url.Parse(raw)
+	switch {
+	case err != nil:
+		d.errorf(path, "cannot parse %q: %v", raw, err)
+	case u.Scheme != "http" && u.Scheme != "https":
+		d.errorf(path, "unsupported scheme %q (must be http or https)", u.Scheme)
+	case u.Host == "":
+		d.errorf(path, "missing host in %q", raw)
+	}
+}
+
+func (d *Diagnostics) validateMethod(path, method string) {
+	if !slices.Contains(supportedMethods, method) {
+		d.errorf(path, "unsupported method %q (must be one of %s)", method, strings.Join(supportedMethods, ", "))
+	}
+}
+
+func (d *Diagnostics) validatePayload(path, method, body, bodyFile string, json map[string]any, form map[string]string) {
+	var set []string
+	if body != "" {
+		set = append(set, "body")
+	}
+	if bodyFile != "" {
+		set = append(set, "body_file")
+	}
+	if json != nil {
+		set = append(set, "json")
+	}
+	if form != nil {
+		set = append(set, "form")
+	}
+
+	if len(set) > 1 {
+		d.errorf(path, "only one of body, body_file, json and form may be set, got %s", strings.Join(set, ", "))
+	}
+	if len(set) > 0 && (method == "GET" || method == "HEAD") {
+		d.warnf(path+"."+set[0], "request body sent with %s; many servers ignore it", method)
+	}
+	if bodyFile != "" {
+		if _, err := os.Stat(bodyFile); err != nil {
+			d.errorf(path+".body_file", "%v", err)
+		}
+	}
+}
+
+func (d *Diagnostics) validateAuth(path string, a domain.Auth) {
+	switch a.Type {
+	case "":
+		return
+	case domain.AuthBasic:
+		if a.Username == "" {
+			d.errorf(path+".username", "is required for basic auth")
+		}
+	case domain.AuthOAuth2:
+		if a.TokenURL == "" {
+			d.errorf(path+".token_url", "is required for oauth2 auth")
+		} else {
+			d.validateURL(path+".token_url", a.TokenURL)
+		}
+		if a.ClientID == "" {
+			d.errorf(path+".client_id", "is required for oauth2 auth")
+		}
+		if a.ClientSecret == "" {
+			d.errorf(path+".client_secret", "is required for oauth2 auth")
+		}
+	case domain.AuthHMAC:
+		if a.Secret == "" {
+			d.errorf(path+".secret", "is required for hmac auth")
+		}
+		if alg := strings.ToLower(a.Algorithm); alg != "" && alg != "sha256" && alg != "sha512" {
+			d.errorf(path+".algorithm", "unsupported algorithm %q (must be sha256 or sha512)", a.Algorithm)
+		}
+	default:
+		d.errorf(path+".type", "unsupported auth type %q (must be basic, oauth2 or hmac)", a.Type)
+		return
+	}
+
+	for _, s := range []struct{ key, ref string }{
+		{"password", a.Password}, {"client_secret", a.ClientSecret}, {"secret", a.Secret},
+	} {
+		key, ref := s.key, s.ref
+		if name, ok := strings.CutPrefix(ref, "env:"); ok {
+			if _, set := os.LookupEnv(name); !set {
+				d.warnf(path+"."+key, "environment variable %s is not set", name)
+			}
+		} else if file, ok := strings.CutPrefix(ref, "file:"); ok {
+			if _, err := os.Stat(file); err != nil {
+				d.warnf(path+"."+key, "%v", err)
+			}
+		}
+	}
+}
+
+func (d *Diagnostics) validateTLS(path string, t domain.TLS) {
+	if t.MinVersion != "" && !slices.Contains(tlsVersions, t.MinVersion) {
+		d.errorf(path+".min_version", "unsupported version %q (must be one of %s)", t.MinVersion, strings.Join(tlsVersions, ", "))
+	}
+	if t.InsecureSkipVerify && t.CAFile != "" {
+		d.warnf(path+".ca_file", "ignored since certificate verification is disabled")
+	}
+
+	if t.CAFile != "" {
+		if pem, err := os.ReadFile(t.CAFile); err != nil {
+			d.errorf(path+".ca_file", "%v", err)
+		} else if !x509.NewCertPool().AppendCertsFromPEM(pem) {
+			d.errorf(path+".ca_file", "no PEM certificates found in %s", t.CAFile)
+		}
+	}
+
+	switch {
+	case t.CertFile == "" && t.KeyFile == "":
+	case t.CertFile == "":
+		d.errorf(path+".cert_file", "is required when key_file is set")
+	case t.KeyFile == "":
+		d.errorf(path+".key_file", "is required when cert_file is set")
+	default:
+		if _, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile); err != nil {
+			d.errorf(path+".cert_file", "%v", err)
+		}
+	}
+}
+
+func (d *Diagnostics) validateProxy(path string, p domain.Proxy) {
+	switch p.URL {
+	case "":
+		if p.Username != "" {
+			d.warnf(path+".username", "ignored without a proxy url")
+		}
+	case domain.ProxyDirect:
+	default:
+		u, err := url.Parse(p.URL)
+		switch {
+		case err != nil:
+			d.errorf(path+".url", "cannot parse %q: %v", p.URL, err)
+		case !slices.Contains(proxySchemes, u.Scheme):
+			d.errorf(path+".url", "unsupported scheme %q (must be one of %s, or %q)",
+				u.Scheme, strings.Join(proxySchemes, ", "), domain.ProxyDirect)
+		case u.Host == "":
+			d.errorf(path+".url", "missing host in %q", p.URL)
+		case u.User != nil:
+			d.warnf(path+".url", "credentials in the url are not redacted; use username and password instead")
+		}
+	}
+
+	for i, host := range p.NoProxy {
+		if strings.TrimSpace(host) == "" {
+			d.errorf(fmt.Sprintf("%s.no_proxy[%d]", path, i), "must not be empty")
+		}
+	}
+}
+
+func (d *Diagnostics) validateStep(path string, t domain.Target, step domain.Step) {
+	switch {
+	case step.URL == "" && t.URL == "":
+		d.errorf(path+".url", "is required when the target has no url")
+	case strings.Contains(step.URL, "{{"):
+		// Rendered at check time
+	case t.URL == "":
+		d.validateURL(path+".url", step.URL)
+	default:
+		if _, err := url.Parse(step.URL); err != nil {
+			d.errorf(path+".url", "cannot parse %q: %v", step.URL, err)
+		}
+	}
+
+	d.validateMethod(path+".method", step.Method)
+	d.validatePayload(path, step.Method, step.Body, step.BodyFile, step.JSON, step.Form)
+
+	if step.ExpectedStatus < 100 || step.ExpectedStatus > 599 {
+		d.errorf(path+".expected_status", "%d is not a valid HTTP status", step.ExpectedStatus)
+	}
+
+	for k, e := range step.Extract {
+		epath := fmt.Sprintf("%s.extract[%d]", path, k)
+		if !templateVar.MatchString(e.Var) {
+			d.errorf(epath+".var", "%q is not a valid variable name", e.Var)
+		}
+
+		sources := 0
+		for _, s := range []string{e.JSONPath, e.Regex, e.Header} {
+			if s != "" {
+				sources++
+			}
+		}
+		if sources != 1 {
+			d.errorf(epath, "exactly one of json_path, regex and header must be set")
+		}
+		if e.Regex != "" {
+			if _, err := regexp.Compile(e.Regex); err != nil {
+				d.errorf(epath+".regex", "%v", err)
+			}
+		}
+	}
+}
+
+// worstCaseCheck returns how long a check can take when every request of every
+// attempt times out.
+func worstCaseCheck(t domain.Target, retry RetryConfig) time.Duration {
+	worst := t.Timeout
+	if len(t.Steps) > 0 {
+		worst *= time.Duration(len(t.Steps))
+	}
+	if retry.MaxAttempts > 1 {
+		worst *= time.Duration(retry.MaxAttempts)
+	}
+	return worst
+}
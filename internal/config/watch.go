@@ -0,0 +1,61 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce coalesces the burst of events editors emit on save.
+const watchDebounce = 500 * time.Millisecond
+
+// Watch calls onChange whenever the config file at path is written, created or
+// replaced. The parent directory is watched so atomic renames used by editors
+// and config management tools are picked up. Blocks until ctx is cancelled.
+func Watch(ctx context.Context, path string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolving config path: %w", err)
+	}
+
+	if err := watcher.Add(filepath.Dir(abs)); err != nil {
+		return fmt.Errorf("watching %s: %w", filepath.Dir(abs), err)
+	}
+
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != abs {
+				continue
+			}
+			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) {
+				debounce = time.After(watchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("Config watcher error: %v", err)
+		case <-debounce:
+			debounce = nil
+			onChange()
+		}
+	}
+}
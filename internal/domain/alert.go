@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // AlertType indicates whether this is a failure or recovery alert.
 type AlertType int
@@ -8,6 +11,8 @@ type AlertType int
 const (
 	AlertTypeFailure AlertType = iota
 	AlertTypeRecovery
+	AlertTypeBurnRate
+	AlertTypeSilenceSummary
 )
 
 func (t AlertType) String() string {
@@ -16,6 +21,10 @@ func (t AlertType) String() string {
 		return "FAILURE"
 	case AlertTypeRecovery:
 		return "RECOVERY"
+	case AlertTypeBurnRate:
+		return "BURN_RATE"
+	case AlertTypeSilenceSummary:
+		return "SILENCE_SUMMARY"
 	default:
 		return "UNKNOWN"
 	}
@@ -51,6 +60,21 @@ type Alert struct {
 	Message   string
 	Severity  Severity
 	Timestamp time.Time
+
+	// SLO and BurnRate are set for burn-rate alerts only.
+	SLO      *SLOStatus
+	BurnRate *BurnRate
+
+	// Summary is set for silence summary alerts only, which have no target.
+	Summary *SilenceSummary
+
+	// IncidentID is the incident the alert belongs to. It is empty for alerts
+	// outside of an incident, such as silence summaries and oneshot runs.
+	IncidentID string
+
+	// Suppressed lists dependent targets whose failure alerts were suppressed
+	// because this target is down.
+	Suppressed []string
 }
 
 // NewFailureAlert creates an alert for a failed health check.
@@ -81,3 +105,33 @@ func NewRecoveryAlert(result CheckResult) Alert {
 		Timestamp: time.Now(),
 	}
 }
+
+// NewBurnRateAlert creates an alert for a target burning its error budget too fast.
+func NewBurnRateAlert(result CheckResult, status SLOStatus, burn BurnRate) Alert {
+	return Alert{
+		Type:      AlertTypeBurnRate,
+		Target:    result.Target,
+		Result:    result,
+		Message:   fmt.Sprintf("Error budget burning %.1fx faster than allowed (%s)", burn.LongRate, burn.Rule),
+		Severity:  burn.Severity,
+		Timestamp: time.Now(),
+		SLO:       &status,
+		BurnRate:  &burn,
+	}
+}
+
+// NewSilenceSummaryAlert creates an alert summarizing what a silence muted.
+func NewSilenceSummaryAlert(summary SilenceSummary) Alert {
+	severity := SeverityInfo
+	if len(summary.Failing) > 0 {
+		severity = SeverityCritical
+	}
+
+	return Alert{
+		Type:      AlertTypeSilenceSummary,
+		Message:   fmt.Sprintf("Silence %s ended, %d alert(s) suppressed", summary.Silence.Label(), len(summary.Alerts)),
+		Severity:  severity,
+		Timestamp: time.Now(),
+		Summary:   &summary,
+	}
+}
@@ -0,0 +1,39 @@
+package domain
+
+// Auth types supported on targets.
+const (
+	AuthBasic  = "basic"
+	AuthOAuth2 = "oauth2"
+	AuthHMAC   = "hmac"
+)
+
+// Auth configures how requests to a target are authenticated.
+//
+// Secret values (Password, ClientSecret, Secret) may be given literally or as
+// a reference resolved on every check: "env:NAME" reads an environment
+// variable and "file:/path" reads a file, so rotated credentials are picked up
+// without a restart.
+type Auth struct {
+	Type string `koanf:"type"`
+
+	// Basic auth
+	Username string `koanf:"username"`
+	Password string `koanf:"password" redact:"true"`
+
+	// OAuth2 client credentials grant
+	TokenURL     string   `koanf:"token_url"`
+	ClientID     string   `koanf:"client_id"`
+	ClientSecret string   `koanf:"client_secret" redact:"true"`
+	Scopes       []string `koanf:"scopes"`
+
+	// HMAC request signing
+	KeyID     string `koanf:"key_id"`
+	Secret    string `koanf:"secret" redact:"true"`
+	Algorithm string `koanf:"algorithm"`
+	Header    string `koanf:"header"`
+}
+
+// Enabled reports whether the target requires authentication.
+func (a Auth) Enabled() bool {
+	return a.Type != ""
+}
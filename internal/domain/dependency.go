@@ -0,0 +1,95 @@
+package domain
+
+import "slices"
+
+// DependencyGraph maps target names to the names of the targets they depend
+// on, as declared with depends_on.
+type DependencyGraph map[string][]string
+
+// NewDependencyGraph builds the dependency graph of targets.
+func NewDependencyGraph(targets []Target) DependencyGraph {
+	g := make(DependencyGraph, len(targets))
+	for _, t := range targets {
+		if len(t.DependsOn) > 0 {
+			g[t.Name] = t.DependsOn
+		}
+	}
+	return g
+}
+
+// Ancestors returns everything name depends on, directly or transitively,
+// nearest first.
+func (g DependencyGraph) Ancestors(name string) []string {
+	var out []string
+	seen := map[string]bool{name: true}
+	queue := slices.Clone(g[name])
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		out = append(out, n)
+		queue = append(queue, g[n]...)
+	}
+	return out
+}
+
+// Dependents returns every target that depends on name, directly or
+// transitively, sorted by name.
+func (g DependencyGraph) Dependents(name string) []string {
+	var out []string
+	for child := range g {
+		if slices.Contains(g.Ancestors(child), name) {
+			out = append(out, child)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Cycle returns a dependency cycle as a path that starts and ends with the
+// same name, or nil if the graph is acyclic.
+func (g DependencyGraph) Cycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(g))
+	var path []string
+
+	var visit func(n string) []string
+	visit = func(n string) []string {
+		switch state[n] {
+		case visiting:
+			i := slices.Index(path, n)
+			return append(slices.Clone(path[i:]), n)
+		case done:
+			return nil
+		}
+		state[n] = visiting
+		path = append(path, n)
+		for _, dep := range g[n] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[n] = done
+		return nil
+	}
+
+	names := make([]string, 0, len(g))
+	for n := range g {
+		names = append(names, n)
+	}
+	slices.Sort(names)
+	for _, n := range names {
+		if cycle := visit(n); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error classes reported by ErrorClass.
+const (
+	ErrorClassAuth  = "auth"
+	ErrorClassProxy = "proxy"
+	ErrorClassCheck = "check"
+)
+
+// AuthError reports that credentials could not be obtained for a target,
+// e.g. a failed OAuth2 token fetch or an unset credential variable. It lets
+// alerts distinguish auth problems from the target being down.
+type AuthError struct {
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("auth: %v", e.Err)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// ProxyError reports that a request failed before a connection to the target
+// was established through the proxy, e.g. the proxy was unreachable or
+// rejected the CONNECT request.
+type ProxyError struct {
+	Proxy string
+	Err   error
+}
+
+func (e *ProxyError) Error() string {
+	return fmt.Sprintf("proxy %s: %v", e.Proxy, e.Err)
+}
+
+func (e *ProxyError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorClass categorises a check error for alerts and reports: "auth" for
+// credential failures, "proxy" for proxy failures and "check" for everything
+// else.
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		return ErrorClassAuth
+	}
+	var proxyErr *ProxyError
+	if errors.As(err, &proxyErr) {
+		return ErrorClassProxy
+	}
+	return ErrorClassCheck
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// PingKind is what a push target's ping reports.
+type PingKind int
+
+const (
+	PingSuccess PingKind = iota
+	PingStart
+	PingFail
+)
+
+func (k PingKind) String() string {
+	switch k {
+	case PingSuccess:
+		return "success"
+	case PingStart:
+		return "start"
+	case PingFail:
+		return "fail"
+	default:
+		return "unknown"
+	}
+}
+
+// Ping is a check-in from a job monitored by a push target. Message is the
+// optional request body, e.g. the output of a failed job.
+type Ping struct {
+	Kind    PingKind
+	Time    time.Time
+	Message string
+}
+
+// MissedPingError reports that a push target received no ping in time.
+type MissedPingError struct {
+	Deadline time.Duration // period + grace
+	Last     time.Time     // zero if no ping was received since startup
+}
+
+func (e *MissedPingError) Error() string {
+	if e.Last.IsZero() {
+		return fmt.Sprintf("no ping received within %s", e.Deadline)
+	}
+	return fmt.Sprintf("no ping received within %s (last ping at %s)", e.Deadline, e.Last.Format(time.RFC3339))
+}
+
+// JobFailedError reports that a job pinged its push target's failure URL.
+type JobFailedError struct {
+	Message string
+}
+
+func (e *JobFailedError) Error() string {
+	if e.Message == "" {
+		return "job reported failure"
+	}
+	return "job reported failure: " + e.Message
+}
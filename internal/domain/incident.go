@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// Incident is one outage of a target: it is opened when the target turns
+// unhealthy and resolved when it recovers. The JSON form is the state file
+// format.
+type Incident struct {
+	ID       string          `json:"id"`
+	Target   string          `json:"target"`
+	Started  time.Time       `json:"started"`
+	Resolved time.Time       `json:"resolved,omitzero"`
+	Ack      *Ack            `json:"ack,omitempty"`
+	Timeline []IncidentEvent `json:"timeline"`
+}
+
+// Open reports whether the incident is not resolved yet.
+func (i Incident) Open() bool {
+	return i.Resolved.IsZero()
+}
+
+// Duration returns how long the incident lasted, or has lasted so far at now.
+func (i Incident) Duration(now time.Time) time.Duration {
+	if !i.Open() {
+		return i.Resolved.Sub(i.Started)
+	}
+	return now.Sub(i.Started)
+}
+
+// IncidentEventType is the kind of an incident timeline entry.
+type IncidentEventType string
+
+const (
+	IncidentOpened   IncidentEventType = "opened"
+	IncidentCheck    IncidentEventType = "check"
+	IncidentAlert    IncidentEventType = "alert"
+	IncidentAck      IncidentEventType = "ack"
+	IncidentNote     IncidentEventType = "note"
+	IncidentResolved IncidentEventType = "resolved"
+)
+
+// IncidentEvent is an entry in an incident's timeline. By is set for acks and
+// notes.
+type IncidentEvent struct {
+	Type    IncidentEventType `json:"type"`
+	Time    time.Time         `json:"time"`
+	Message string            `json:"message"`
+	By      string            `json:"by,omitempty"`
+}
@@ -0,0 +1,29 @@
+package domain
+
+// ProxyDirect as a proxy URL disables proxying, including proxies set through
+// the HTTP_PROXY family of environment variables.
+const ProxyDirect = "direct"
+
+// Proxy configures the proxy requests are sent through. URL may use the http,
+// https, socks5 or socks5h scheme; when empty, the HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY environment variables apply. Hosts matching NoProxy (host names,
+// domain suffixes such as ".internal", IPs or CIDR ranges) are reached
+// directly.
+type Proxy struct {
+	URL      string   `koanf:"url"`
+	NoProxy  []string `koanf:"no_proxy"`
+	Username string   `koanf:"username"`
+	Password string   `koanf:"password" redact:"true"`
+}
+
+// Merge returns p with unset fields taken from defaults. Credentials are only
+// inherited along with the proxy URL.
+func (p Proxy) Merge(defaults Proxy) Proxy {
+	if p.URL == "" {
+		p.URL, p.Username, p.Password = defaults.URL, defaults.Username, defaults.Password
+	}
+	if p.NoProxy == nil {
+		p.NoProxy = defaults.NoProxy
+	}
+	return p
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Silence mutes alerts for matching targets while it is active. Checks keep
+// running and results are still recorded; only sending alerts is skipped.
+//
+// A silence matches targets whose name matches one of Targets (glob patterns
+// such as "api-*" are allowed) and that carry all of Labels. At least one of
+// the two must be set; if both are, a target must satisfy both.
+//
+// A one-off maintenance window sets Starts and Ends. A recurring one sets
+// Windows, evaluated in TimeZone (an IANA name, local time if empty), and may
+// additionally be bounded by Starts and Ends.
+type Silence struct {
+	ID        string            `koanf:"-"`
+	Name      string            `koanf:"name"`
+	Targets   []string          `koanf:"targets"`
+	Labels    map[string]string `koanf:"labels"`
+	Starts    time.Time         `koanf:"starts"`
+	Ends      time.Time         `koanf:"ends"`
+	Windows   []Window          `koanf:"windows"`
+	TimeZone  string            `koanf:"timezone"`
+	Comment   string            `koanf:"comment"`
+	CreatedBy string            `koanf:"-"`
+}
+
+// Validate reports the first problem with the silence.
+func (s Silence) Validate() error {
+	if len(s.Targets) == 0 && len(s.Labels) == 0 {
+		return fmt.Errorf("targets or labels must be set")
+	}
+	if err := ValidatePatterns(s.Targets); err != nil {
+		return err
+	}
+	if s.Ends.IsZero() && len(s.Windows) == 0 {
+		return fmt.Errorf("ends or windows must be set")
+	}
+	if !s.Starts.IsZero() && !s.Ends.IsZero() && !s.Ends.After(s.Starts) {
+		return fmt.Errorf("ends must be after starts")
+	}
+	for i, w := range s.Windows {
+		if err := w.Validate(); err != nil {
+			return fmt.Errorf("windows[%d]: %w", i, err)
+		}
+	}
+	if _, err := time.LoadLocation(s.TimeZone); err != nil {
+		return fmt.Errorf("unknown timezone %q", s.TimeZone)
+	}
+	return nil
+}
+
+// Matches reports whether the silence applies to target.
+func (s Silence) Matches(target Target) bool {
+	if len(s.Targets) == 0 && len(s.Labels) == 0 {
+		return false
+	}
+	return target.Matches(s.Targets, s.Labels)
+}
+
+// Active reports whether the silence is in effect at t.
+func (s Silence) Active(t time.Time) bool {
+	if !s.Starts.IsZero() && t.Before(s.Starts) {
+		return false
+	}
+	if !s.Ends.IsZero() && !t.Before(s.Ends) {
+		return false
+	}
+	if len(s.Windows) == 0 {
+		return true
+	}
+
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return false
+	}
+	for _, w := range s.Windows {
+		if w.Contains(t.In(loc)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired reports whether the silence can no longer become active after t.
+func (s Silence) Expired(t time.Time) bool {
+	return !s.Ends.IsZero() && !t.Before(s.Ends)
+}
+
+// Label returns the silence name, or its ID if it has none.
+func (s Silence) Label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.ID
+}
+
+// SilenceSummary reports what happened to the targets of a silence while it
+// was active, and is sent when it ends.
+type SilenceSummary struct {
+	Silence Silence
+	Started time.Time
+	Ended   time.Time
+
+	// Alerts lists the alerts that were not sent, oldest first.
+	Alerts []Alert
+
+	// Failing lists targets whose last suppressed alert was a failure, so
+	// they are presumably still down.
+	Failing []string
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// SLO declares an availability objective for a target.
+type SLO struct {
+	// Objective is the target availability in percent, e.g. 99.9.
+	Objective float64       `koanf:"objective"`
+	Window    time.Duration `koanf:"window"`
+}
+
+// Enabled reports whether an objective has been declared.
+func (s SLO) Enabled() bool {
+	return s.Objective > 0
+}
+
+// ErrorBudget returns the allowed failure ratio, e.g. 0.001 for 99.9%.
+func (s SLO) ErrorBudget() float64 {
+	return 1 - s.Objective/100
+}
+
+// SLOStatus is a point-in-time view of a target's SLO compliance.
+type SLOStatus struct {
+	Objective       float64
+	Window          time.Duration
+	Availability    float64 // ratio of the monitored time the target was up, 0..1
+	BudgetRemaining float64 // ratio of error budget left, negative when exhausted
+	Checks          int
+	Failures        int
+}
+
+// BurnRate describes a multi-window burn-rate rule that is firing.
+type BurnRate struct {
+	Rule        string
+	LongWindow  time.Duration
+	ShortWindow time.Duration
+	Threshold   float64
+	LongRate    float64
+	ShortRate   float64
+	Severity    Severity
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// TargetStatus is a snapshot of a monitored target's health.
+type TargetStatus struct {
+	Target Target
+	Status HealthStatus
+	// Since is when the target entered Status, zero before its first check.
+	Since time.Time
+	// Last is the most recent check result, nil before the first check.
+	Last *CheckResult
+	// Ack is set while a failing target is acknowledged.
+	Ack *Ack
+	// Incident is the ID of the target's open incident, if any.
+	Incident string
+}
+
+// Ack records that someone is handling a failing target. It is cleared when
+// the target recovers.
+type Ack struct {
+	By string    `json:"by"`
+	At time.Time `json:"at"`
+}
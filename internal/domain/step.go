@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// Step is one HTTP request of a multi-step transaction check. String fields
+// may reference variables extracted by earlier steps as Go templates, e.g.
+// "Bearer {{.token}}". A relative URL is resolved against the target URL.
+type Step struct {
+	Name           string            `koanf:"name"`
+	URL            string            `koanf:"url"`
+	Method         string            `koanf:"method"`
+	ExpectedStatus int               `koanf:"expected_status"`
+	Headers        map[string]string `koanf:"headers" redact:"true"`
+	Body           string            `koanf:"body" redact:"true"`
+	BodyFile       string            `koanf:"body_file"`
+	JSON           map[string]any    `koanf:"json" redact:"true"`
+	Form           map[string]string `koanf:"form" redact:"true"`
+	BodyContains   string            `koanf:"body_contains"`
+	MaxLatency     LatencyLimits     `koanf:"max_latency"`
+	Extract        []Extraction      `koanf:"extract"`
+}
+
+// Extraction captures a value from a step response into a variable. Exactly
+// one of JSONPath, Regex and Header is set. For Regex, the first capture group
+// is used if present, otherwise the whole match.
+type Extraction struct {
+	Var      string `koanf:"var"`
+	JSONPath string `koanf:"json_path"`
+	Regex    string `koanf:"regex"`
+	Header   string `koanf:"header"`
+}
+
+// StepResult is the outcome of one step of a transaction check.
+type StepResult struct {
+	Name         string
+	URL          string
+	ActualStatus int
+	Latency      time.Duration
+	Timing       Timing
+	Proxy        string
+	Error        error
+}
@@ -1,16 +1,143 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"path"
+	"slices"
+	"time"
+)
 
-// Target represents a URL endpoint to be health-checked.
+// Target represents a URL endpoint to be health-checked, or, with Type
+// "push", a heartbeat monitor that expects pings instead.
 type Target struct {
-	Name           string            `koanf:"name"`
-	URL            string            `koanf:"url"`
+	Name string `koanf:"name"`
+
+	// Type is "http" (the default) or "push". A push target is not checked:
+	// it expects a ping on /ping/<Token> at least every Period, and URL,
+	// Method, Interval and the other request settings do not apply to it.
+	Type string `koanf:"type"`
+	URL  string `koanf:"url"`
+
+	// Labels are free-form key/value pairs that silences can match on, e.g.
+	// team = "payments".
+	Labels         map[string]string `koanf:"labels"`
 	ExpectedStatus int               `koanf:"expected_status"`
 	Method         string            `koanf:"method"`
 	Timeout        time.Duration     `koanf:"timeout"`
 	Interval       time.Duration     `koanf:"interval"`
-	Headers        map[string]string `koanf:"headers"`
+
+	// FailingInterval, if set, replaces Interval while the target is pending
+	// or unhealthy, for faster confirmation and recovery detection.
+	FailingInterval time.Duration `koanf:"failing_interval"`
+
+	// FailureThreshold is how many consecutive failed checks make the target
+	// unhealthy and alert; until then it is pending.
+	FailureThreshold int `koanf:"failure_threshold"`
+
+	// DependsOn names targets this one depends on, such as a gateway in front
+	// of it. While any of them is pending or unhealthy, this target's failure
+	// alerts are suppressed and listed in the dependency's alerts instead.
+	DependsOn []string `koanf:"depends_on"`
+
+	// Schedule is a cron expression ("0 2 * * *") used instead of Interval.
+	Schedule string `koanf:"schedule"`
+
+	// TimeZone is the IANA name Schedule and ActiveWindows are evaluated in,
+	// default local time.
+	TimeZone string `koanf:"timezone"`
+
+	// ActiveWindows restricts checks to recurring time windows; outside them
+	// the target is paused and never alerts.
+	ActiveWindows []Window `koanf:"active_windows"`
+
+	// At most one of Body, BodyFile, JSON (serialised to JSON) and Form
+	// (URL-encoded) may be set; the Content-Type header is derived from it
+	// unless set explicitly in Headers.
+	Headers  map[string]string `koanf:"headers" redact:"true"`
+	Body     string            `koanf:"body" redact:"true"`
+	BodyFile string            `koanf:"body_file"`
+	JSON     map[string]any    `koanf:"json" redact:"true"`
+	Form     map[string]string `koanf:"form" redact:"true"`
+	Auth     Auth              `koanf:"auth"`
+
+	// KeepAlive reuses pooled connections between checks; set it to false to
+	// open a cold connection on every check and measure real handshake
+	// latency.
+	KeepAlive *bool `koanf:"keep_alive"`
+
+	// Redirects are followed up to MaxRedirects (DefaultMaxRedirects if
+	// unset; 0 fails on any redirect) unless FollowRedirects is false, in
+	// which case the redirect response itself is checked.
+	FollowRedirects *bool `koanf:"follow_redirects"`
+	MaxRedirects    *int  `koanf:"max_redirects"`
+
+	// ExpectedFinalURL asserts where the check ended up, e.g. that a health
+	// URL has not started redirecting to a login page. A value without scheme
+	// and host (such as "/health") is compared against the final path only.
+	ExpectedFinalURL string `koanf:"expected_final_url"`
+
+	// TLS and Proxy settings left unset fall back to the global [http.tls]
+	// and [http.proxy] settings.
+	TLS        TLS           `koanf:"tls"`
+	Proxy      Proxy         `koanf:"proxy"`
+	SLO        SLO           `koanf:"slo"`
+	MaxLatency LatencyLimits `koanf:"max_latency"`
+
+	// Steps make the target a transaction: the steps run in order as a single
+	// check. Headers, Timeout, KeepAlive and the redirect policy apply to
+	// every step and URL is the base for relative step URLs; Method,
+	// ExpectedStatus, ExpectedFinalURL and the payload fields are ignored.
+	Steps []Step `koanf:"steps"`
+
+	// Token is the secret in a push target's ping URL. The target turns
+	// unhealthy when no ping arrives within Period + Grace, or when the job
+	// pings /ping/<Token>/fail.
+	Token  string        `koanf:"token" redact:"true"`
+	Period time.Duration `koanf:"period"`
+	Grace  time.Duration `koanf:"grace"`
+}
+
+// DefaultMaxRedirects is how many redirects a target follows unless it sets
+// max_redirects.
+const DefaultMaxRedirects = 10
+
+// Target types.
+const (
+	TargetTypeHTTP = "http"
+	TargetTypePush = "push"
+)
+
+// Matches reports whether the target's name matches one of patterns (glob
+// patterns such as "api-*") and it carries all of labels. Empty patterns or
+// labels match any target.
+func (t Target) Matches(patterns []string, labels map[string]string) bool {
+	if len(patterns) > 0 && !slices.ContainsFunc(patterns, func(p string) bool {
+		ok, _ := path.Match(p, t.Name)
+		return ok
+	}) {
+		return false
+	}
+	for k, v := range labels {
+		if t.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidatePatterns reports the first malformed target name pattern.
+func ValidatePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid target pattern %q", p)
+		}
+	}
+	return nil
+}
+
+// Push reports whether the target is a push (heartbeat) monitor.
+func (t Target) Push() bool {
+	return t.Type == TargetTypePush
 }
 
 // CheckResult represents the outcome of a health check.
@@ -20,8 +147,32 @@ type CheckResult struct {
 	ActualStatus int
 	Error        error
 	Latency      time.Duration
+	Timing       Timing
 	Timestamp    time.Time
 	Attempts     int
+
+	// Proxy is the proxy the check went through, empty for direct requests.
+	Proxy string
+
+	// Redirects lists the responses that redirected the request, in order,
+	// and FinalURL is the URL of the last request made.
+	Redirects []Redirect
+	FinalURL  string
+
+	// Steps and FailedStep are set for transaction checks only.
+	Steps      []StepResult
+	FailedStep string
+
+	// RunDuration is set for push targets when a job's success or failure
+	// ping follows its start ping: the time the job took.
+	RunDuration time.Duration
+}
+
+// Redirect is one hop of a redirect chain: the URL requested and the status
+// it answered with.
+type Redirect struct {
+	URL        string
+	StatusCode int
 }
 
 // HealthStatus represents the overall health state of a target.
@@ -31,6 +182,8 @@ const (
 	StatusHealthy HealthStatus = iota
 	StatusUnhealthy
 	StatusUnknown
+	StatusPaused
+	StatusPending
 )
 
 func (s HealthStatus) String() string {
@@ -39,6 +192,10 @@ func (s HealthStatus) String() string {
 		return "healthy"
 	case StatusUnhealthy:
 		return "unhealthy"
+	case StatusPaused:
+		return "paused"
+	case StatusPending:
+		return "pending"
 	default:
 		return "unknown"
 	}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Timing breaks the latency of a request down into its phases. Phases that did
+// not happen, such as DNS lookup and connect on a reused connection, are zero.
+type Timing struct {
+	DNSLookup    time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	// TimeToFirstByte is the time from sending the request to receiving the
+	// first response byte, i.e. server processing time.
+	TimeToFirstByte time.Duration
+	ContentTransfer time.Duration
+	ConnReused      bool
+}
+
+// IsZero reports whether no phase was recorded.
+func (t Timing) IsZero() bool {
+	return t == Timing{}
+}
+
+func (t Timing) String() string {
+	parts := []string{
+		"dns=" + t.DNSLookup.Round(time.Microsecond).String(),
+		"connect=" + t.Connect.Round(time.Microsecond).String(),
+		"tls=" + t.TLSHandshake.Round(time.Microsecond).String(),
+		"ttfb=" + t.TimeToFirstByte.Round(time.Microsecond).String(),
+		"transfer=" + t.ContentTransfer.Round(time.Microsecond).String(),
+	}
+	if t.ConnReused {
+		parts = append(parts, "reused")
+	}
+	return strings.Join(parts, " ")
+}
+
+// LatencyLimits are upper bounds on the total latency and individual request
+// phases. Zero values are not enforced.
+type LatencyLimits struct {
+	Total           time.Duration `koanf:"total"`
+	DNSLookup       time.Duration `koanf:"dns"`
+	Connect         time.Duration `koanf:"connect"`
+	TLSHandshake    time.Duration `koanf:"tls"`
+	TimeToFirstByte time.Duration `koanf:"ttfb"`
+	ContentTransfer time.Duration `koanf:"transfer"`
+}
+
+// Check returns an error describing the first limit exceeded by the measured
+// latency and timing, or nil if all limits hold.
+func (l LatencyLimits) Check(latency time.Duration, timing Timing) error {
+	checks := []struct {
+		phase string
+		limit time.Duration
+		value time.Duration
+	}{
+		{"total", l.Total, latency},
+		{"dns", l.DNSLookup, timing.DNSLookup},
+		{"connect", l.Connect, timing.Connect},
+		{"tls", l.TLSHandshake, timing.TLSHandshake},
+		{"ttfb", l.TimeToFirstByte, timing.TimeToFirstByte},
+		{"transfer", l.ContentTransfer, timing.ContentTransfer},
+	}
+
+	for _, c := range checks {
+		if c.limit > 0 && c.value > c.limit {
+			return fmt.Errorf("latency assertion failed: %s %s > %s",
+				c.phase, c.value.Round(time.Millisecond), c.limit)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package domain
+
+// TLS holds TLS settings for a target. File paths are re-read when the files
+// change on disk, so rotated certificates are picked up without a restart.
+type TLS struct {
+	// CAFile is a PEM bundle of CAs used instead of the system roots.
+	CAFile string `koanf:"ca_file"`
+
+	// CertFile and KeyFile are the PEM client certificate and key for mTLS.
+	CertFile string `koanf:"cert_file"`
+	KeyFile  string `koanf:"key_file"`
+
+	// ServerName overrides the name used for SNI and certificate verification.
+	ServerName string `koanf:"server_name"`
+
+	// MinVersion is the minimum TLS version: "1.0", "1.1", "1.2" or "1.3".
+	MinVersion string `koanf:"min_version"`
+
+	InsecureSkipVerify bool `koanf:"insecure_skip_verify"`
+}
+
+// Merge returns t with unset fields taken from defaults.
+func (t TLS) Merge(defaults TLS) TLS {
+	if t.CAFile == "" {
+		t.CAFile = defaults.CAFile
+	}
+	if t.CertFile == "" && t.KeyFile == "" {
+		t.CertFile, t.KeyFile = defaults.CertFile, defaults.KeyFile
+	}
+	if t.ServerName == "" {
+		t.ServerName = defaults.ServerName
+	}
+	if t.MinVersion == "" {
+		t.MinVersion = defaults.MinVersion
+	}
+	t.InsecureSkipVerify = t.InsecureSkipVerify || defaults.InsecureSkipVerify
+	return t
+}
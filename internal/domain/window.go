@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// weekdays maps the day names accepted in windows to time.Weekday.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Window is a recurring time window, e.g. business hours. Days lists day
+// names ("mon") or ranges ("mon-fri"); empty means every day. Start and End
+// are "HH:MM" in the target's time zone. A window whose End is before its
+// Start runs overnight and belongs to the day it starts on.
+type Window struct {
+	Days  []string `koanf:"days"`
+	Start string   `koanf:"start"`
+	End   string   `koanf:"end"`
+}
+
+// Validate reports the first invalid field of the window.
+func (w Window) Validate() error {
+	if _, err := ParseDays(w.Days); err != nil {
+		return err
+	}
+	start, err := ParseTimeOfDay(w.Start)
+	if err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	end, err := ParseTimeOfDay(w.End)
+	if err != nil {
+		return fmt.Errorf("end: %w", err)
+	}
+	if start == end {
+		return fmt.Errorf("start and end must differ")
+	}
+	return nil
+}
+
+// Contains reports whether t, in the time zone the window applies to, falls
+// within the window. Invalid windows contain nothing.
+func (w Window) Contains(t time.Time) bool {
+	days, err := ParseDays(w.Days)
+	if err != nil {
+		return false
+	}
+	start, err1 := ParseTimeOfDay(w.Start)
+	end, err2 := ParseTimeOfDay(w.End)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	// The wall-clock time of day, not the time elapsed since midnight, which
+	// is an hour off on days the clocks change
+	offset := time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second +
+		time.Duration(t.Nanosecond())
+
+	if start < end {
+		return days[t.Weekday()] && offset >= start && offset < end
+	}
+	// Overnight: the evening part of a listed day, or the morning after one
+	return (days[t.Weekday()] && offset >= start) ||
+		(days[(t.Weekday()+6)%7] && offset < end)
+}
+
+// ParseDays parses day names and ranges into a set of weekdays. An empty list
+// means every day.
+func ParseDays(names []string) (map[time.Weekday]bool, error) {
+	days := make(map[time.Weekday]bool, 7)
+	if len(names) == 0 {
+		for _, d := range weekdays {
+			days[d] = true
+		}
+		return days, nil
+	}
+
+	for _, name := range names {
+		from, to, isRange := strings.Cut(strings.ToLower(strings.TrimSpace(name)), "-")
+		first, ok := weekdays[from]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", name)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[to]; !ok {
+				return nil, fmt.Errorf("unknown day %q", name)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// ParseTimeOfDay parses "HH:MM" into the offset from midnight.
+func ParseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowContainsOnDSTChangeDays(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	w := Window{Start: "09:00", End: "17:00"}
+
+	tests := []struct {
+		name string
+		time time.Time
+		want bool
+	}{
+		// Clocks go forward at 02:00 on 2026-03-29: 09:00 is 8h after midnight
+		{"spring forward, start", time.Date(2026, 3, 29, 9, 0, 0, 0, berlin), true},
+		{"spring forward, before start", time.Date(2026, 3, 29, 8, 59, 0, 0, berlin), false},
+		{"spring forward, before end", time.Date(2026, 3, 29, 16, 59, 0, 0, berlin), true},
+		{"spring forward, end", time.Date(2026, 3, 29, 17, 0, 0, 0, berlin), false},
+		// Clocks go back at 03:00 on 2026-10-25: 09:00 is 10h after midnight
+		{"fall back, start", time.Date(2026, 10, 25, 9, 0, 0, 0, berlin), true},
+		{"fall back, before start", time.Date(2026, 10, 25, 8, 59, 0, 0, berlin), false},
+		{"fall back, before end", time.Date(2026, 10, 25, 16, 59, 0, 0, berlin), true},
+		{"fall back, end", time.Date(2026, 10, 25, 17, 0, 0, 0, berlin), false},
+	}
+	for _, tt := range tests {
+		if got := w.Contains(tt.time); got != tt.want {
+			t.Errorf("%s: Contains(%s) = %t, want %t", tt.name, tt.time.Format("15:04 MST"), got, tt.want)
+		}
+	}
+}
+
+func TestWindowContainsOvernight(t *testing.T) {
+	w := Window{Days: []string{"fri"}, Start: "22:00", End: "06:00"}
+
+	tests := []struct {
+		time time.Time
+		want bool
+	}{
+		{time.Date(2026, 1, 2, 23, 0, 0, 0, time.UTC), true},  // Friday evening
+		{time.Date(2026, 1, 3, 5, 59, 0, 0, time.UTC), true},  // Saturday morning
+		{time.Date(2026, 1, 3, 6, 0, 0, 0, time.UTC), false},  // Saturday, window over
+		{time.Date(2026, 1, 3, 23, 0, 0, 0, time.UTC), false}, // Saturday evening
+	}
+	for _, tt := range tests {
+		if got := w.Contains(tt.time); got != tt.want {
+			t.Errorf("Contains(%s) = %t, want %t", tt.time.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"github.com/raha-io/joghd/internal/alerter"
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// Alerter records every alert it is sent.
+type Alerter struct {
+	mu      sync.Mutex
+	alerts  []domain.Alert
+	err     error
+	changed chan struct{}
+}
+
+var _ alerter.Alerter = (*Alerter)(nil)
+
+// NewAlerter returns an empty recording alerter.
+func NewAlerter() *Alerter {
+	return &Alerter{changed: make(chan struct{})}
+}
+
+// Send records alert and returns the error set with FailWith, if any. Failed
+// sends are recorded too.
+func (a *Alerter) Send(_ context.Context, alert domain.Alert) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.alerts = append(a.alerts, alert)
+	close(a.changed)
+	a.changed = make(chan struct{})
+	return a.err
+}
+
+// Name returns "fake".
+func (a *Alerter) Name() string {
+	return "fake"
+}
+
+// FailWith makes subsequent sends return err; nil restores success.
+func (a *Alerter) FailWith(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.err = err
+}
+
+// Alerts returns the alerts recorded so far, in order.
+func (a *Alerter) Alerts() []domain.Alert {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]domain.Alert(nil), a.alerts...)
+}
+
+// Types returns the types of the recorded alerts, in order, which is often
+// all a test needs to compare.
+func (a *Alerter) Types() []domain.AlertType {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	types := make([]domain.AlertType, len(a.alerts))
+	for i, alert := range a.alerts {
+		types[i] = alert.Type
+	}
+	return types
+}
+
+// Wait blocks until at least n alerts were recorded and returns them, or
+// returns the context error.
+func (a *Alerter) Wait(ctx context.Context, n int) ([]domain.Alert, error) {
+	for {
+		a.mu.Lock()
+		alerts, changed := a.alerts, a.changed
+		a.mu.Unlock()
+
+		if len(alerts) >= n {
+			return append([]domain.Alert(nil), alerts...), nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Reset forgets the recorded alerts.
+func (a *Alerter) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.alerts = nil
+}
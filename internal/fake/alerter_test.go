@@ -0,0 +1,67 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+func TestAlerterRecordsAlertsInOrder(t *testing.T) {
+	a := NewAlerter()
+	ctx := context.Background()
+
+	a.Send(ctx, domain.Alert{Type: domain.AlertTypeFailure})
+	a.Send(ctx, domain.Alert{Type: domain.AlertTypeRecovery})
+
+	want := []domain.AlertType{domain.AlertTypeFailure, domain.AlertTypeRecovery}
+	if got := a.Types(); !slices.Equal(got, want) {
+		t.Errorf("Types() = %v, want %v", got, want)
+	}
+
+	a.Reset()
+	if got := a.Alerts(); len(got) != 0 {
+		t.Errorf("Alerts() after Reset = %v, want none", got)
+	}
+}
+
+func TestAlerterFailWith(t *testing.T) {
+	a := NewAlerter()
+	errDown := errors.New("telegram down")
+
+	a.FailWith(errDown)
+	if err := a.Send(context.Background(), domain.Alert{}); !errors.Is(err, errDown) {
+		t.Errorf("Send() = %v, want %v", err, errDown)
+	}
+	a.FailWith(nil)
+	if err := a.Send(context.Background(), domain.Alert{}); err != nil {
+		t.Errorf("Send() = %v, want nil", err)
+	}
+	if got := len(a.Alerts()); got != 2 {
+		t.Errorf("recorded %d alerts, want 2 including the failed one", got)
+	}
+}
+
+func TestAlerterWait(t *testing.T) {
+	a := NewAlerter()
+	go a.Send(context.Background(), domain.Alert{Type: domain.AlertTypeFailure})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	alerts, err := a.Wait(ctx, 1)
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if alerts[0].Type != domain.AlertTypeFailure {
+		t.Errorf("got %s alert, want FAILURE", alerts[0].Type)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := a.Wait(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait for a missing alert = %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,214 @@
+package fake
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/raha-io/joghd/internal/clock"
+)
+
+// Clock is a clock.Clock whose time only moves through Advance and Set.
+// Timers, tickers, After and Sleep fire when the time passes their deadline.
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+	changed chan struct{}
+}
+
+// waiter is a pending timer, ticker or sleep.
+type waiter struct {
+	deadline time.Time
+	period   time.Duration // for tickers
+	c        chan time.Time
+}
+
+var _ clock.Clock = (*Clock)(nil)
+
+// NewClock returns a fake clock set to now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now, changed: make(chan struct{})}
+}
+
+// Now returns the fake time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Since returns the fake time elapsed since t.
+func (c *Clock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// After returns a channel that receives the fake time once d has passed.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Sleep blocks until the clock has been advanced by d.
+func (c *Clock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// NewTimer returns a timer firing once d has passed.
+func (c *Clock) NewTimer(d time.Duration) clock.Timer {
+	t := &fakeTimer{clock: c, w: &waiter{c: make(chan time.Time, 1)}}
+	t.Reset(d)
+	return t
+}
+
+// NewTicker returns a ticker firing every d. Like time.Ticker, it drops ticks
+// for slow receivers.
+func (c *Clock) NewTicker(d time.Duration) clock.Ticker {
+	if d <= 0 {
+		panic("fake: non-positive interval for NewTicker")
+	}
+	t := &fakeTicker{clock: c, w: &waiter{c: make(chan time.Time, 1)}}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing due timers and tickers in
+// deadline order.
+func (c *Clock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to t, firing due timers and tickers in deadline order.
+// Moving backwards fires nothing.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		sort.Slice(c.waiters, func(i, j int) bool {
+			return c.waiters[i].deadline.Before(c.waiters[j].deadline)
+		})
+		if len(c.waiters) == 0 || c.waiters[0].deadline.After(t) {
+			break
+		}
+
+		w := c.waiters[0]
+		c.now = w.deadline
+		select {
+		case w.c <- c.now:
+		default:
+		}
+
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+		} else {
+			c.waiters = c.waiters[1:]
+		}
+	}
+	c.now = t
+	c.notify()
+}
+
+// Waiters returns the number of pending timers, tickers and sleeps.
+func (c *Clock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil blocks until at least n timers, tickers or sleeps are pending.
+// Tests use it to wait for a goroutine to reach its next wait before
+// advancing the clock.
+func (c *Clock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		pending, changed := len(c.waiters), c.changed
+		c.mu.Unlock()
+
+		if pending >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+func (c *Clock) add(w *waiter) {
+	c.remove(w)
+	c.waiters = append(c.waiters, w)
+	c.notify()
+}
+
+func (c *Clock) remove(w *waiter) bool {
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.notify()
+			return true
+		}
+	}
+	return false
+}
+
+// notify wakes BlockUntil callers. It must be called with c.mu held.
+func (c *Clock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+type fakeTimer struct {
+	clock *Clock
+	w     *waiter
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.w.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t.w)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := t.clock.remove(t.w)
+	if d <= 0 {
+		select {
+		case t.w.c <- t.clock.now:
+		default:
+		}
+		return active
+	}
+	t.w.deadline = t.clock.now.Add(d)
+	t.clock.add(t.w)
+	return active
+}
+
+type fakeTicker struct {
+	clock *Clock
+	w     *waiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.w.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.remove(t.w)
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("fake: non-positive interval for Ticker.Reset")
+	}
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.w.period = d
+	t.w.deadline = t.clock.now.Add(d)
+	t.clock.add(t.w)
+}
@@ -0,0 +1,103 @@
+package fake
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestClockTimerFiresOnceDeadlinePasses(t *testing.T) {
+	clk := NewClock(epoch)
+	timer := clk.NewTimer(10 * time.Second)
+
+	clk.Advance(9 * time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired before its deadline")
+	default:
+	}
+
+	clk.Advance(time.Second)
+	select {
+	case got := <-timer.C():
+		if want := epoch.Add(10 * time.Second); !got.Equal(want) {
+			t.Errorf("timer fired at %s, want %s", got, want)
+		}
+	default:
+		t.Fatal("timer did not fire at its deadline")
+	}
+	if n := clk.Waiters(); n != 0 {
+		t.Errorf("Waiters() = %d after the timer fired, want 0", n)
+	}
+}
+
+func TestClockTimerStop(t *testing.T) {
+	clk := NewClock(epoch)
+	timer := clk.NewTimer(time.Second)
+
+	if !timer.Stop() {
+		t.Error("Stop() = false for a pending timer")
+	}
+	clk.Advance(time.Minute)
+	select {
+	case <-timer.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+	if timer.Stop() {
+		t.Error("Stop() = true for a stopped timer")
+	}
+}
+
+func TestClockTickerRepeats(t *testing.T) {
+	clk := NewClock(epoch)
+	ticker := clk.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for i := 1; i <= 3; i++ {
+		clk.Advance(30 * time.Second)
+		select {
+		case got := <-ticker.C():
+			if want := epoch.Add(time.Duration(i) * 30 * time.Second); !got.Equal(want) {
+				t.Errorf("tick %d at %s, want %s", i, got, want)
+			}
+		default:
+			t.Fatalf("tick %d missing", i)
+		}
+	}
+}
+
+func TestClockAdvanceFiresInDeadlineOrder(t *testing.T) {
+	clk := NewClock(epoch)
+	late := clk.NewTimer(20 * time.Second)
+	early := clk.NewTimer(10 * time.Second)
+
+	clk.Advance(time.Minute)
+	if got, want := (<-early.C()), epoch.Add(10*time.Second); !got.Equal(want) {
+		t.Errorf("early timer fired at %s, want %s", got, want)
+	}
+	if got, want := (<-late.C()), epoch.Add(20*time.Second); !got.Equal(want) {
+		t.Errorf("late timer fired at %s, want %s", got, want)
+	}
+	if got, want := clk.Now(), epoch.Add(time.Minute); !got.Equal(want) {
+		t.Errorf("Now() = %s, want %s", got, want)
+	}
+}
+
+func TestClockSleepAndBlockUntil(t *testing.T) {
+	clk := NewClock(epoch)
+	done := make(chan struct{})
+	go func() {
+		clk.Sleep(5 * time.Second)
+		close(done)
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(5 * time.Second)
+	<-done
+
+	if got := clk.Since(epoch); got != 5*time.Second {
+		t.Errorf("Since(epoch) = %s, want 5s", got)
+	}
+}
@@ -0,0 +1,24 @@
+// Package fake provides deterministic stand-ins for the network, the clock and
+// alert delivery, for tests of the checker and scheduler:
+//
+//   - Prober is a scriptable checker.HTTPClient returning sequences of
+//     statuses, errors and latencies per URL.
+//   - Clock is a clock.Clock that only moves when advanced, so intervals and
+//     retry backoff run without real sleeps.
+//   - Alerter records the alerts it is sent.
+//
+// A typical test scripts the prober, runs the scheduler on the fake clock and
+// advances it check by check:
+//
+//	clk := fake.NewClock(time.Unix(0, 0))
+//	prober := fake.NewProber(clk).
+//		Script("https://api/health", fake.Status(200), fake.Status(500))
+//	alerts := fake.NewAlerter()
+//	chk := checker.New(checker.WithHTTPClient(prober), checker.WithClock(clk))
+//	sched := scheduler.New(chk, alerts, targets, scheduler.WithClock(clk))
+//	go sched.Start(ctx)
+//
+//	clk.BlockUntil(1) // the target loop waits on its ticker
+//	clk.Advance(30 * time.Second)
+//	got, err := alerts.Wait(ctx, 1) // the failure alert
+package fake
@@ -0,0 +1,143 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/raha-io/joghd/internal/checker"
+	"github.com/raha-io/joghd/internal/clock"
+)
+
+// Step is one scripted response of a Prober. A non-nil Err fails the request;
+// otherwise the request answers with Status, Header and Body after Latency.
+type Step struct {
+	Status  int
+	Err     error
+	Latency time.Duration
+	Header  http.Header
+	Body    []byte
+}
+
+// Status returns a step answering with code.
+func Status(code int) Step {
+	return Step{Status: code}
+}
+
+// Slow returns a step answering with code after latency.
+func Slow(code int, latency time.Duration) Step {
+	return Step{Status: code, Latency: latency}
+}
+
+// Fail returns a step failing with err, like a connection error.
+func Fail(err error) Step {
+	return Step{Err: err}
+}
+
+// Repeat returns n copies of step.
+func Repeat(n int, step Step) []Step {
+	steps := make([]Step, n)
+	for i := range steps {
+		steps[i] = step
+	}
+	return steps
+}
+
+// ErrNoScript is returned for requests to URLs without a script.
+var ErrNoScript = errors.New("fake: no script for url")
+
+// Prober is a checker.HTTPClient answering from per-URL scripts. Each request
+// consumes the next step of its URL's script; the last step repeats once the
+// script is exhausted. Requests are recorded for later inspection.
+type Prober struct {
+	clock clock.Clock
+
+	mu       sync.Mutex
+	scripts  map[string][]Step
+	requests []checker.Request
+}
+
+var _ checker.HTTPClient = (*Prober)(nil)
+
+// NewProber returns a prober that waits out step latencies on clk. With a nil
+// clock latencies are reported but not waited for.
+func NewProber(clk clock.Clock) *Prober {
+	return &Prober{clock: clk, scripts: make(map[string][]Step)}
+}
+
+// Script appends steps to the script for url.
+func (p *Prober) Script(url string, steps ...Step) *Prober {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scripts[url] = append(p.scripts[url], steps...)
+	return p
+}
+
+// Requests returns the requests executed so far, in order.
+func (p *Prober) Requests() []checker.Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]checker.Request(nil), p.requests...)
+}
+
+// Calls returns how many requests were made to url.
+func (p *Prober) Calls(url string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := 0
+	for _, req := range p.requests {
+		if req.URL == url {
+			n++
+		}
+	}
+	return n
+}
+
+// Execute answers req with the next step of its URL's script.
+func (p *Prober) Execute(ctx context.Context, req checker.Request) (checker.Response, error) {
+	step, err := p.next(req)
+	if err != nil {
+		return checker.Response{}, err
+	}
+
+	if step.Latency > 0 && p.clock != nil {
+		select {
+		case <-ctx.Done():
+			return checker.Response{}, ctx.Err()
+		case <-p.clock.After(step.Latency):
+		}
+	}
+
+	resp := checker.Response{
+		Latency:  step.Latency,
+		Header:   step.Header,
+		Body:     step.Body,
+		FinalURL: req.URL,
+	}
+	if step.Err != nil {
+		return resp, step.Err
+	}
+	resp.StatusCode = step.Status
+	return resp, nil
+}
+
+func (p *Prober) next(req checker.Request) (Step, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests = append(p.requests, req)
+
+	script := p.scripts[req.URL]
+	if len(script) == 0 {
+		return Step{}, fmt.Errorf("%w %s", ErrNoScript, req.URL)
+	}
+	step := script[0]
+	if len(script) > 1 {
+		p.scripts[req.URL] = script[1:]
+	}
+	return step, nil
+}
@@ -0,0 +1,72 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/checker"
+)
+
+const healthURL = "https://api.example.com/health"
+
+func TestProberFollowsScriptAndRepeatsLastStep(t *testing.T) {
+	errRefused := errors.New("connection refused")
+	p := NewProber(nil).Script(healthURL, Status(200), Fail(errRefused), Status(503))
+
+	want := []struct {
+		status int
+		err    error
+	}{
+		{200, nil},
+		{0, errRefused},
+		{503, nil},
+		{503, nil},
+	}
+	for i, w := range want {
+		resp, err := p.Execute(context.Background(), checker.Request{URL: healthURL})
+		if resp.StatusCode != w.status || !errors.Is(err, w.err) {
+			t.Errorf("request %d: got (%d, %v), want (%d, %v)", i+1, resp.StatusCode, err, w.status, w.err)
+		}
+	}
+	if got := p.Calls(healthURL); got != len(want) {
+		t.Errorf("Calls() = %d, want %d", got, len(want))
+	}
+}
+
+func TestProberWithoutScript(t *testing.T) {
+	p := NewProber(nil)
+
+	_, err := p.Execute(context.Background(), checker.Request{URL: healthURL})
+	if !errors.Is(err, ErrNoScript) {
+		t.Errorf("err = %v, want ErrNoScript", err)
+	}
+	if got := len(p.Requests()); got != 1 {
+		t.Errorf("recorded %d requests, want 1", got)
+	}
+}
+
+func TestProberWaitsOutLatencyOnClock(t *testing.T) {
+	clk := NewClock(epoch)
+	p := NewProber(clk).Script(healthURL, Slow(200, 2*time.Second))
+
+	done := make(chan checker.Response)
+	go func() {
+		resp, _ := p.Execute(context.Background(), checker.Request{URL: healthURL})
+		done <- resp
+	}()
+
+	clk.BlockUntil(1)
+	select {
+	case <-done:
+		t.Fatal("response arrived before its latency passed")
+	default:
+	}
+
+	clk.Advance(2 * time.Second)
+	resp := <-done
+	if resp.StatusCode != 200 || resp.Latency != 2*time.Second {
+		t.Errorf("got status %d after %s, want 200 after 2s", resp.StatusCode, resp.Latency)
+	}
+}
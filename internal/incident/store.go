@@ -0,0 +1,385 @@
+// Package incident keeps track of target outages as incidents with a
+// timeline, and persists them to a JSON state file.
+package incident
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raha-io/joghd/internal/clock"
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+const (
+	// maxTimeline caps the events kept per incident. The opening event is
+	// always kept; older events after it make room for new ones.
+	maxTimeline = 100
+	// maxResolved caps the resolved incidents kept, whatever the retention,
+	// so the state file rewritten on every change stays small.
+	maxResolved = 500
+)
+
+var (
+	// ErrNotFound is returned for unknown incident IDs.
+	ErrNotFound = errors.New("incident not found")
+	// ErrResolved is returned when acknowledging a resolved incident.
+	ErrResolved = errors.New("incident is resolved")
+)
+
+// Store holds the open incidents of every target and the most recent
+// resolved ones within the retention period. Every change is written to the
+// state file, if set.
+type Store struct {
+	path      string
+	retention time.Duration
+	clock     clock.Clock
+	random    func(n int) int
+
+	mu        sync.Mutex
+	incidents []*domain.Incident          // oldest first
+	open      map[string]*domain.Incident // target name -> open incident
+}
+
+// state is the content of the state file.
+type state struct {
+	Incidents []domain.Incident `json:"incidents"`
+}
+
+// Option is a functional option for configuring the store.
+type Option func(*Store)
+
+// WithStateFile persists incidents to path. Without it incidents are kept in
+// memory only.
+func WithStateFile(path string) Option {
+	return func(s *Store) {
+		s.path = path
+	}
+}
+
+// WithRetention drops resolved incidents once they are older than d. Zero
+// keeps them forever.
+func WithRetention(d time.Duration) Option {
+	return func(s *Store) {
+		s.retention = d
+	}
+}
+
+// WithClock sets the clock used for acks, notes and retention.
+func WithClock(clk clock.Clock) Option {
+	return func(s *Store) {
+		s.clock = clk
+	}
+}
+
+// New creates an empty store. Call Load to restore the state file.
+func New(opts ...Option) *Store {
+	s := &Store{
+		clock:  clock.Real(),
+		random: rand.IntN,
+		open:   make(map[string]*domain.Incident),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Load restores incidents from the state file. A missing file is not an
+// error.
+func (s *Store) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("parsing %s: %w", s.path, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.incidents = nil
+	clear(s.open)
+	for _, inc := range st.Incidents {
+		s.incidents = append(s.incidents, &inc)
+		if inc.Open() {
+			s.open[inc.Target] = &inc
+		}
+	}
+
+	log.Printf("Loaded %d incident(s), %d open, from %s", len(s.incidents), len(s.open), s.path)
+	return nil
+}
+
+// Open opens an incident for target at the given time, unless one is already
+// open, and returns it. The second return value is true if it was opened now.
+func (s *Store) Open(target string, at time.Time, message string) (domain.Incident, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if inc, ok := s.open[target]; ok {
+		return *inc, false
+	}
+
+	inc := &domain.Incident{
+		ID:      s.newID(at),
+		Target:  target,
+		Started: at,
+		Timeline: []domain.IncidentEvent{
+			{Type: domain.IncidentOpened, Time: at, Message: message},
+		},
+	}
+	s.incidents = append(s.incidents, inc)
+	s.open[target] = inc
+	s.save()
+
+	log.Printf("Opened incident %s for %s", inc.ID, target)
+	return *inc, true
+}
+
+// Current returns the open incident of target, if any.
+func (s *Store) Current(target string) (domain.Incident, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if inc, ok := s.open[target]; ok {
+		return *inc, true
+	}
+	return domain.Incident{}, false
+}
+
+// Record adds an event to the open incident of target and returns the
+// incident's ID, or "" if there is none. A check event repeating the message
+// of the incident's previous check event is dropped, so a target failing the
+// same way for hours does not grow its timeline.
+func (s *Store) Record(target string, event domain.IncidentEvent) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inc, ok := s.open[target]
+	if !ok {
+		return ""
+	}
+	if event.Type == domain.IncidentCheck && lastCheck(inc) == event.Message {
+		return inc.ID
+	}
+	addEvent(inc, event)
+	s.save()
+	return inc.ID
+}
+
+// Resolve resolves the open incident of target, if any, and returns it.
+func (s *Store) Resolve(target string, at time.Time, message string) (domain.Incident, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inc, ok := s.open[target]
+	if !ok {
+		return domain.Incident{}, false
+	}
+	inc.Resolved = at
+	addEvent(inc, domain.IncidentEvent{Type: domain.IncidentResolved, Time: at, Message: message})
+	delete(s.open, target)
+	s.save()
+
+	log.Printf("Resolved incident %s for %s after %s", inc.ID, target, inc.Duration(at).Round(time.Second))
+	return *inc, true
+}
+
+// Ack records that by is handling the incident with the given ID.
+func (s *Store) Ack(id, by string) (domain.Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inc, err := s.find(id)
+	if err != nil {
+		return domain.Incident{}, err
+	}
+	if !inc.Open() {
+		return domain.Incident{}, fmt.Errorf("%w: %s", ErrResolved, inc.ID)
+	}
+
+	now := s.clock.Now()
+	inc.Ack = &domain.Ack{By: by, At: now}
+	addEvent(inc, domain.IncidentEvent{Type: domain.IncidentAck, Time: now, Message: "acknowledged", By: by})
+	s.save()
+	return *inc, nil
+}
+
+// Note adds a note by by to the incident with the given ID. Resolved
+// incidents take notes too, e.g. for a postmortem.
+func (s *Store) Note(id, by, message string) (domain.Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inc, err := s.find(id)
+	if err != nil {
+		return domain.Incident{}, err
+	}
+	addEvent(inc, domain.IncidentEvent{Type: domain.IncidentNote, Time: s.clock.Now(), Message: message, By: by})
+	s.save()
+	return *inc, nil
+}
+
+// Get returns the incident with the given ID.
+func (s *Store) Get(id string) (domain.Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inc, err := s.find(id)
+	if err != nil {
+		return domain.Incident{}, err
+	}
+	return *inc, nil
+}
+
+// List returns all incidents, newest first.
+func (s *Store) List() []domain.Incident {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+	out := make([]domain.Incident, 0, len(s.incidents))
+	for _, inc := range slices.Backward(s.incidents) {
+		out = append(out, *inc)
+	}
+	return out
+}
+
+// idAlphabet is Crockford's base32, which leaves out letters easily mistaken
+// for digits.
+const idAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newID returns an unused ID for an incident started at the given time, such
+// as INC-20260314-7KQ3. The random suffix keeps IDs unique across restarts,
+// also without a state file to remember the IDs already handed out. Must be
+// called with mu held.
+func (s *Store) newID(at time.Time) string {
+	suffix := make([]byte, 4)
+	for {
+		for i := range suffix {
+			suffix[i] = idAlphabet[s.random(len(idAlphabet))]
+		}
+		id := "INC-" + at.UTC().Format("20060102") + "-" + string(suffix)
+		if _, err := s.find(id); err != nil {
+			return id
+		}
+	}
+}
+
+// find returns the incident with the given ID, ignoring case. Must be called
+// with mu held.
+func (s *Store) find(id string) (*domain.Incident, error) {
+	for _, inc := range s.incidents {
+		if strings.EqualFold(inc.ID, id) {
+			return inc, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// prune drops resolved incidents older than the retention period, and the
+// oldest resolved ones beyond maxResolved. Must be called with mu held.
+func (s *Store) prune() {
+	resolved := 0
+	for _, inc := range s.incidents {
+		if !inc.Open() {
+			resolved++
+		}
+	}
+	cutoff := s.clock.Now().Add(-s.retention)
+	s.incidents = slices.DeleteFunc(s.incidents, func(inc *domain.Incident) bool {
+		if inc.Open() {
+			return false
+		}
+		if resolved > maxResolved || (s.retention > 0 && inc.Resolved.Before(cutoff)) {
+			resolved--
+			return true
+		}
+		return false
+	})
+}
+
+// save writes the state file, replacing it atomically. Failures are logged,
+// as losing the history must not stop monitoring. Must be called with mu
+// held.
+func (s *Store) save() {
+	s.prune()
+	if s.path == "" {
+		return
+	}
+
+	st := state{Incidents: make([]domain.Incident, 0, len(s.incidents))}
+	for _, inc := range s.incidents {
+		st.Incidents = append(st.Incidents, *inc)
+	}
+	if err := writeFile(s.path, st); err != nil {
+		log.Printf("Failed to save incidents: %v", err)
+	}
+}
+
+// writeFile writes v as JSON to a temporary file next to path, syncs it and
+// renames it over path, so a crash leaves either the old or the new file.
+func writeFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// addEvent appends event to the timeline of inc, dropping the oldest events
+// after the opening one beyond maxTimeline.
+func addEvent(inc *domain.Incident, event domain.IncidentEvent) {
+	inc.Timeline = append(inc.Timeline, event)
+	if over := len(inc.Timeline) - maxTimeline; over > 0 {
+		inc.Timeline = slices.Delete(inc.Timeline, 1, 1+over)
+	}
+}
+
+// lastCheck returns the message of the last check event of inc.
+func lastCheck(inc *domain.Incident) string {
+	for _, e := range slices.Backward(inc.Timeline) {
+		if e.Type == domain.IncidentCheck {
+			return e.Message
+		}
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package incident
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/fake"
+)
+
+var started = time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+// sequence returns a random source that draws the indexes of suffixes from
+// idAlphabet in turn.
+func sequence(suffixes ...string) func(int) int {
+	var draws []int
+	for _, suffix := range suffixes {
+		for _, c := range suffix {
+			draws = append(draws, strings.IndexRune(idAlphabet, c))
+		}
+	}
+	return func(int) int {
+		i := draws[0]
+		draws = draws[1:]
+		return i
+	}
+}
+
+func TestIDsAreUniqueAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incidents.json")
+
+	s := New(WithStateFile(path))
+	s.random = sequence("7KQ3")
+	first, _ := s.Open("API", started, "down")
+	if first.ID != "INC-20260314-7KQ3" {
+		t.Fatalf("ID = %q, want INC-20260314-7KQ3", first.ID)
+	}
+
+	// After a restart the same suffix comes up again for the same day
+	restarted := New(WithStateFile(path))
+	if err := restarted.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	restarted.random = sequence("7KQ3", "7KQ3", "M2ZD")
+	second, _ := restarted.Open("DB", started.Add(time.Hour), "down")
+	if second.ID != "INC-20260314-M2ZD" {
+		t.Errorf("ID = %q, want the first unused suffix INC-20260314-M2ZD", second.ID)
+	}
+}
+
+func TestTimelineIsCapped(t *testing.T) {
+	s := New()
+	s.Open("API", started, "down")
+	for i := range 2 * maxTimeline {
+		s.Record("API", domain.IncidentEvent{
+			Type: domain.IncidentCheck, Time: started.Add(time.Duration(i) * time.Minute), Message: fmt.Sprintf("error %d", i),
+		})
+	}
+	inc, _ := s.Resolve("API", started.Add(time.Duration(2*maxTimeline)*time.Minute), "recovered")
+
+	if got := len(inc.Timeline); got != maxTimeline {
+		t.Fatalf("timeline has %d events, want %d", got, maxTimeline)
+	}
+	if first := inc.Timeline[0]; first.Type != domain.IncidentOpened {
+		t.Errorf("first event is %s, want the opening event", first.Type)
+	}
+	if last := inc.Timeline[maxTimeline-1]; last.Type != domain.IncidentResolved {
+		t.Errorf("last event is %s, want the resolution", last.Type)
+	}
+	if got, want := inc.Timeline[maxTimeline-2].Message, fmt.Sprintf("error %d", 2*maxTimeline-1); got != want {
+		t.Errorf("last check event = %q, want the latest %q", got, want)
+	}
+}
+
+func TestResolvedIncidentsAreCapped(t *testing.T) {
+	s := New()
+	at := started
+	for i := range maxResolved + 10 {
+		target := fmt.Sprintf("target-%d", i)
+		s.Open(target, at, "down")
+		at = at.Add(time.Minute)
+		s.Resolve(target, at, "recovered")
+	}
+	s.Open("API", at, "down")
+
+	list := s.List()
+	if got := len(list); got != maxResolved+1 {
+		t.Fatalf("kept %d incidents, want %d resolved and the open one", got, maxResolved)
+	}
+	if list[0].Target != "API" || !list[0].Open() {
+		t.Errorf("newest incident is %s, want the open API incident", list[0].Target)
+	}
+	if got := list[len(list)-1].Target; got != "target-10" {
+		t.Errorf("oldest incident kept is %s, want target-10", got)
+	}
+}
+
+func TestStateFileSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incidents.json")
+	clk := fake.NewClock(started)
+
+	s := New(WithStateFile(path), WithClock(clk))
+	inc, _ := s.Open("API", started, "down")
+	if _, err := s.Ack(inc.ID, "@alice"); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+
+	restarted := New(WithStateFile(path), WithClock(clk))
+	if err := restarted.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	current, ok := restarted.Current("API")
+	if !ok || current.ID != inc.ID {
+		t.Fatalf("open incident after restart = %q, want %q", current.ID, inc.ID)
+	}
+	if current.Ack == nil || current.Ack.By != "@alice" {
+		t.Errorf("ack after restart = %+v, want by @alice", current.Ack)
+	}
+
+	// A new incident after a restart gets a new ID
+	restarted.Resolve("API", started.Add(time.Minute), "recovered")
+	next, _ := restarted.Open("API", started.Add(2*time.Minute), "down again")
+	if next.ID == inc.ID {
+		t.Errorf("reopened incident reuses ID %s", inc.ID)
+	}
+	if _, err := restarted.Ack(inc.ID, "@bob"); !errors.Is(err, ErrResolved) {
+		t.Errorf("Ack of resolved incident = %v, want ErrResolved", err)
+	}
+}
+
+func TestLoadWithoutStateFile(t *testing.T) {
+	s := New(WithStateFile(filepath.Join(t.TempDir(), "missing.json")))
+	if err := s.Load(); err != nil {
+		t.Errorf("Load of a missing state file: %v", err)
+	}
+	if got := s.List(); len(got) != 0 {
+		t.Errorf("List() = %v, want none", got)
+	}
+}
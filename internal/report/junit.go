@@ -0,0 +1,100 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// writeJUnit renders one testcase per target.
+func writeJUnit(w io.Writer, r Report) error {
+	suite := junitTestSuite{
+		Name:      "joghd",
+		Tests:     len(r.Results),
+		Failures:  r.Failures(),
+		Time:      seconds(r.Duration),
+		Timestamp: r.Started.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, result := range r.Results {
+		tc := junitTestCase{
+			Name:      result.Target.Name,
+			Classname: "joghd",
+			Time:      seconds(result.Latency),
+			SystemOut: fmt.Sprintf("%s %s -> %d (expected %d) in %d attempt(s)",
+				result.Target.Method, result.Target.URL, result.ActualStatus,
+				result.Target.ExpectedStatus, result.Attempts),
+		}
+		if !result.Timing.IsZero() {
+			tc.SystemOut += "\ntiming: " + result.Timing.String()
+		}
+		for _, step := range result.Steps {
+			tc.SystemOut += fmt.Sprintf("\nstep %q: %d in %s", step.Name, step.ActualStatus, step.Latency.Round(time.Millisecond))
+			if step.Error != nil {
+				tc.SystemOut += fmt.Sprintf(" (%v)", step.Error)
+			}
+		}
+		if !result.Success {
+			tc.Failure = &junitFailure{
+				Message: failureMessage(result),
+				Type:    "HealthCheckFailure",
+				Body:    tc.SystemOut,
+			}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	doc := junitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,47 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// writeMarkdown renders a summary table, e.g. for a CI job summary.
+func writeMarkdown(w io.Writer, r Report) error {
+	var b strings.Builder
+
+	failed := r.Failures()
+	fmt.Fprintf(&b, "## Joghd health check: %d/%d passed\n\n", len(r.Results)-failed, len(r.Results))
+	b.WriteString("| Status | Target | URL | HTTP | Latency | Attempts | Error |\n")
+	b.WriteString("| ------ | ------ | --- | ---- | ------- | -------- | ----- |\n")
+
+	for _, result := range r.Results {
+		status := "✅"
+		errMsg := ""
+		if !result.Success {
+			status = "❌"
+			errMsg = failureMessage(result)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %s | %d | %s |\n",
+			status,
+			markdownEscape(result.Target.Name),
+			markdownEscape(result.Target.URL),
+			result.ActualStatus,
+			result.Latency.Round(time.Millisecond),
+			result.Attempts,
+			markdownEscape(errMsg),
+		)
+	}
+
+	fmt.Fprintf(&b, "\nCompleted in %s.\n", r.Duration.Round(time.Millisecond))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscape keeps cell contents from breaking the table.
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
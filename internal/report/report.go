@@ -0,0 +1,209 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// Format is a machine-readable report format.
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatJUnit    Format = "junit"
+	FormatTAP      Format = "tap"
+	FormatMarkdown Format = "markdown"
+)
+
+// Formats lists all supported report formats.
+var Formats = []Format{FormatJSON, FormatJUnit, FormatTAP, FormatMarkdown}
+
+// ParseFormat returns the format with the given name.
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown report format %q (must be json, junit, tap or markdown)", name)
+}
+
+// Report is the outcome of a oneshot run.
+type Report struct {
+	Started  time.Time
+	Duration time.Duration
+	Results  []domain.CheckResult
+}
+
+// Failures returns the number of failed checks.
+func (r Report) Failures() int {
+	n := 0
+	for _, result := range r.Results {
+		if !result.Success {
+			n++
+		}
+	}
+	return n
+}
+
+// Write renders the report in the given format.
+func Write(w io.Writer, format Format, r Report) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, r)
+	case FormatJUnit:
+		return writeJUnit(w, r)
+	case FormatTAP:
+		return writeTAP(w, r)
+	case FormatMarkdown:
+		return writeMarkdown(w, r)
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// Result is the JSON form of a check result.
+type Result struct {
+	Name           string     `json:"name"`
+	URL            string     `json:"url"`
+	Method         string     `json:"method"`
+	ExpectedStatus int        `json:"expected_status"`
+	ActualStatus   int        `json:"actual_status"`
+	Success        bool       `json:"success"`
+	Attempts       int        `json:"attempts"`
+	LatencyMS      float64    `json:"latency_ms"`
+	Timing         *Timing    `json:"timing,omitempty"`
+	Proxy          string     `json:"proxy,omitempty"`
+	Redirects      []Redirect `json:"redirects,omitempty"`
+	FinalURL       string     `json:"final_url,omitempty"`
+	Steps          []Step     `json:"steps,omitempty"`
+	FailedStep     string     `json:"failed_step,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	ErrorClass     string     `json:"error_class,omitempty"`
+	Timestamp      time.Time  `json:"timestamp"`
+}
+
+// Redirect is one hop of a redirect chain.
+type Redirect struct {
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+}
+
+// NewResult converts a check result to its JSON form.
+func NewResult(result domain.CheckResult) Result {
+	r := Result{
+		Name:           result.Target.Name,
+		URL:            result.Target.URL,
+		Method:         result.Target.Method,
+		ExpectedStatus: result.Target.ExpectedStatus,
+		ActualStatus:   result.ActualStatus,
+		Success:        result.Success,
+		Attempts:       result.Attempts,
+		LatencyMS:      milliseconds(result.Latency),
+		Timestamp:      result.Timestamp,
+	}
+	if result.Error != nil {
+		r.Error = result.Error.Error()
+		r.ErrorClass = domain.ErrorClass(result.Error)
+	}
+	r.Timing = newTiming(result.Timing)
+	r.Proxy = result.Proxy
+	for _, redirect := range result.Redirects {
+		r.Redirects = append(r.Redirects, Redirect{URL: redirect.URL, Status: redirect.StatusCode})
+	}
+	if len(result.Redirects) > 0 {
+		r.FinalURL = result.FinalURL
+	}
+	r.FailedStep = result.FailedStep
+	for _, step := range result.Steps {
+		s := Step{
+			Name:         step.Name,
+			URL:          step.URL,
+			ActualStatus: step.ActualStatus,
+			LatencyMS:    milliseconds(step.Latency),
+			Timing:       newTiming(step.Timing),
+		}
+		if step.Error != nil {
+			s.Error = step.Error.Error()
+		}
+		r.Steps = append(r.Steps, s)
+	}
+	return r
+}
+
+// Step is the JSON form of a transaction step result.
+type Step struct {
+	Name         string  `json:"name"`
+	URL          string  `json:"url"`
+	ActualStatus int     `json:"actual_status"`
+	LatencyMS    float64 `json:"latency_ms"`
+	Timing       *Timing `json:"timing,omitempty"`
+	Error        string  `json:"error,omitempty"`
+}
+
+func newTiming(t domain.Timing) *Timing {
+	if t.IsZero() {
+		return nil
+	}
+	return &Timing{
+		DNSLookupMS:       milliseconds(t.DNSLookup),
+		ConnectMS:         milliseconds(t.Connect),
+		TLSHandshakeMS:    milliseconds(t.TLSHandshake),
+		TimeToFirstByteMS: milliseconds(t.TimeToFirstByte),
+		ContentTransferMS: milliseconds(t.ContentTransfer),
+		ConnReused:        t.ConnReused,
+	}
+}
+
+// Timing is the JSON form of a request's phase breakdown.
+type Timing struct {
+	DNSLookupMS       float64 `json:"dns_lookup_ms"`
+	ConnectMS         float64 `json:"connect_ms"`
+	TLSHandshakeMS    float64 `json:"tls_handshake_ms"`
+	TimeToFirstByteMS float64 `json:"ttfb_ms"`
+	ContentTransferMS float64 `json:"content_transfer_ms"`
+	ConnReused        bool    `json:"conn_reused"`
+}
+
+type jsonReport struct {
+	Started    time.Time `json:"started"`
+	DurationMS float64   `json:"duration_ms"`
+	Total      int       `json:"total"`
+	Passed     int       `json:"passed"`
+	Failed     int       `json:"failed"`
+	Results    []Result  `json:"results"`
+}
+
+func writeJSON(w io.Writer, r Report) error {
+	out := jsonReport{
+		Started:    r.Started,
+		DurationMS: milliseconds(r.Duration),
+		Total:      len(r.Results),
+		Failed:     r.Failures(),
+		Results:    make([]Result, len(r.Results)),
+	}
+	out.Passed = out.Total - out.Failed
+	for i, result := range r.Results {
+		out.Results[i] = NewResult(result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// failureMessage describes why a check failed.
+func failureMessage(result domain.CheckResult) string {
+	if result.Error != nil {
+		return result.Error.Error()
+	}
+	return "health check failed"
+}
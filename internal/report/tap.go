@@ -0,0 +1,51 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// writeTAP renders a TAP version 13 stream with a YAML diagnostic block for
+// each failed target.
+func writeTAP(w io.Writer, r Report) error {
+	var b strings.Builder
+
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", len(r.Results))
+
+	for i, result := range r.Results {
+		status := "ok"
+		if !result.Success {
+			status = "not ok"
+		}
+		fmt.Fprintf(&b, "%s %d - %s\n", status, i+1, tapEscape(result.Target.Name))
+
+		if !result.Success {
+			b.WriteString("  ---\n")
+			fmt.Fprintf(&b, "  message: %q\n", failureMessage(result))
+			fmt.Fprintf(&b, "  url: %q\n", result.Target.URL)
+			fmt.Fprintf(&b, "  method: %s\n", result.Target.Method)
+			fmt.Fprintf(&b, "  expected_status: %d\n", result.Target.ExpectedStatus)
+			fmt.Fprintf(&b, "  actual_status: %d\n", result.ActualStatus)
+			fmt.Fprintf(&b, "  attempts: %d\n", result.Attempts)
+			fmt.Fprintf(&b, "  latency: %s\n", result.Latency.Round(time.Millisecond))
+			if result.FailedStep != "" {
+				fmt.Fprintf(&b, "  failed_step: %q\n", result.FailedStep)
+			}
+			if !result.Timing.IsZero() {
+				fmt.Fprintf(&b, "  timing: %q\n", result.Timing.String())
+			}
+			b.WriteString("  ...\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// tapEscape keeps a description from being parsed as a directive.
+func tapEscape(s string) string {
+	return strings.ReplaceAll(s, "#", `\#`)
+}
@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// pingBuffer is how many pings a push target queues before dropping them.
+const pingBuffer = 16
+
+// ErrUnknownMonitor is returned by Ping for tokens of no push target.
+var ErrUnknownMonitor = errors.New("unknown push monitor")
+
+// Ping delivers a ping to the push target with the given token.
+func (s *Scheduler) Ping(token string, kind domain.PingKind, message string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, t := range s.targets {
+		if !t.Push() || t.Token != token {
+			continue
+		}
+		pings, ok := s.pings[t.Name]
+		if !ok {
+			return ErrUnknownMonitor
+		}
+		select {
+		case pings <- domain.Ping{Kind: kind, Time: s.clock.Now(), Message: message}:
+		default:
+			log.Printf("Dropped %s ping for %s: too many pending pings", kind, t.Name)
+		}
+		return nil
+	}
+	return ErrUnknownMonitor
+}
+
+// runPushLoop evaluates a push target from the pings it receives. A success or
+// failure ping resets the deadline to period + grace from now; once it passes
+// without a ping, the target fails and is re-evaluated every period until the
+// next ping. Run durations are measured from a start ping to the next success
+// or failure ping. A request on rechecks re-evaluates the last failure.
+func (s *Scheduler) runPushLoop(ctx context.Context, target domain.Target, pings <-chan domain.Ping, rechecks <-chan recheck) {
+	deadline := target.Period + target.Grace
+	timer := s.clock.NewTimer(deadline)
+	defer timer.Stop()
+
+	var lastPing, started time.Time
+	var last *domain.CheckResult
+	for {
+		var result domain.CheckResult
+		select {
+		case <-ctx.Done():
+			return
+		case <-rechecks:
+			if last != nil && !last.Success {
+				s.evaluate(ctx, target, *last)
+			}
+			continue
+		case p := <-pings:
+			if p.Kind == domain.PingStart {
+				if !started.IsZero() {
+					log.Printf("Job %s started again before finishing", target.Name)
+				}
+				started = p.Time
+				log.Printf("Job %s started", target.Name)
+				continue
+			}
+
+			result = domain.CheckResult{Target: target, Success: true, Timestamp: p.Time, Attempts: 1}
+			if p.Kind == domain.PingFail {
+				result.Success = false
+				result.Error = &domain.JobFailedError{Message: p.Message}
+			}
+			if !started.IsZero() {
+				result.RunDuration = p.Time.Sub(started)
+				result.Latency = result.RunDuration
+				log.Printf("Job %s finished in %s (%s)", target.Name, result.RunDuration.Round(time.Millisecond), p.Kind)
+				started = time.Time{}
+			}
+			lastPing = p.Time
+			timer.Reset(deadline)
+		case <-timer.C():
+			result = domain.CheckResult{
+				Target:    target,
+				Error:     &domain.MissedPingError{Deadline: deadline, Last: lastPing},
+				Timestamp: s.clock.Now(),
+				Attempts:  1,
+			}
+			timer.Reset(target.Period)
+		}
+
+		last = &result
+		s.evaluate(ctx, target, result)
+	}
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/fake"
+)
+
+const pushToken = "nightly-7f3a"
+
+func pushTarget() domain.Target {
+	return domain.Target{
+		Name:             "Nightly backup",
+		Type:             domain.TargetTypePush,
+		Token:            pushToken,
+		Period:           time.Hour,
+		Grace:            5 * time.Minute,
+		FailureThreshold: 1,
+	}
+}
+
+// wait waits for the nth alert, counting from 1, and returns it.
+func (h *harness) wait(t *testing.T, n int) domain.Alert {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	alerts, err := h.alerter.Wait(ctx, n)
+	if err != nil {
+		t.Fatalf("waiting for alert %d: %v (got %v)", n, err, h.alerter.Types())
+	}
+	return alerts[n-1]
+}
+
+// waitStatus waits for the named target to reach status, for pings that
+// change the status without alerting.
+func (h *harness) waitStatus(t *testing.T, name string, status domain.HealthStatus) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for h.sched.GetStatus(name) != status {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s is %s, want %s", name, h.sched.GetStatus(name), status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (h *harness) ping(t *testing.T, kind domain.PingKind, message string) {
+	t.Helper()
+	if err := h.sched.Ping(pushToken, kind, message); err != nil {
+		t.Fatalf("%s ping: %v", kind, err)
+	}
+}
+
+func TestPushTargetTimesOutAfterPeriodAndGrace(t *testing.T) {
+	tgt := pushTarget()
+	h := startAll(t, []domain.Target{tgt}, nil)
+	h.next()
+
+	h.clock.Advance(30 * time.Minute)
+	lastPing := h.clock.Now()
+	h.ping(t, domain.PingSuccess, "")
+	h.waitStatus(t, tgt.Name, domain.StatusHealthy)
+
+	// The ping moved the deadline to period + grace from now
+	h.clock.Advance(tgt.Period + tgt.Grace - time.Second)
+	if got := h.alerter.Types(); len(got) != 0 {
+		t.Fatalf("alerts a second before the deadline = %v, want none", got)
+	}
+
+	h.clock.Advance(time.Second)
+	alert := h.wait(t, 1)
+	var missed *domain.MissedPingError
+	if alert.Type != domain.AlertTypeFailure || !errors.As(alert.Result.Error, &missed) {
+		t.Fatalf("got %s alert (%v), want a failure for the missed ping", alert.Type, alert.Result.Error)
+	}
+	if missed.Deadline != tgt.Period+tgt.Grace || !missed.Last.Equal(lastPing) {
+		t.Errorf("missed ping deadline %s after %s, want %s after %s",
+			missed.Deadline, missed.Last, tgt.Period+tgt.Grace, lastPing)
+	}
+
+	// The next ping recovers the target
+	h.next()
+	h.ping(t, domain.PingSuccess, "")
+	if alert := h.wait(t, 2); alert.Type != domain.AlertTypeRecovery {
+		t.Errorf("got %s alert after the ping, want RECOVERY", alert.Type)
+	}
+	if got := h.sched.GetStatus(tgt.Name); got != domain.StatusHealthy {
+		t.Errorf("status = %s, want %s", got, domain.StatusHealthy)
+	}
+}
+
+func TestPushTargetReportsJobFailureAndDuration(t *testing.T) {
+	tgt := pushTarget()
+	h := startAll(t, []domain.Target{tgt}, nil)
+	h.next()
+
+	h.ping(t, domain.PingStart, "")
+	h.clock.Advance(90 * time.Second)
+	h.ping(t, domain.PingFail, "disk full")
+
+	alert := h.wait(t, 1)
+	var failed *domain.JobFailedError
+	if alert.Type != domain.AlertTypeFailure || !errors.As(alert.Result.Error, &failed) {
+		t.Fatalf("got %s alert (%v), want a failure reported by the job", alert.Type, alert.Result.Error)
+	}
+	if failed.Message != "disk full" {
+		t.Errorf("failure message = %q, want %q", failed.Message, "disk full")
+	}
+	if got := alert.Result.RunDuration; got != 90*time.Second {
+		t.Errorf("failed run took %s, want 90s from its start ping", got)
+	}
+
+	h.ping(t, domain.PingStart, "")
+	h.clock.Advance(30 * time.Second)
+	h.ping(t, domain.PingSuccess, "")
+
+	alert = h.wait(t, 2)
+	if alert.Type != domain.AlertTypeRecovery {
+		t.Fatalf("got %s alert, want RECOVERY", alert.Type)
+	}
+	if got := alert.Result.RunDuration; got != 30*time.Second {
+		t.Errorf("successful run took %s, want 30s from its start ping", got)
+	}
+	want := []domain.AlertType{domain.AlertTypeFailure, domain.AlertTypeRecovery}
+	if got := h.alerter.Types(); !slices.Equal(got, want) {
+		t.Errorf("alerts = %v, want %v", got, want)
+	}
+}
+
+func TestPingWithUnknownToken(t *testing.T) {
+	h := startAll(t, []domain.Target{pushTarget(), target(time.Minute, 1)}, map[string][]fake.Step{healthURL: {fake.Status(200)}})
+	h.next()
+
+	if err := h.sched.Ping("wrong", domain.PingSuccess, ""); !errors.Is(err, ErrUnknownMonitor) {
+		t.Errorf("Ping with an unknown token = %v, want ErrUnknownMonitor", err)
+	}
+}
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"log"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// Incidents returns all incidents, newest first.
+func (s *Scheduler) Incidents() []domain.Incident {
+	return s.incidents.List()
+}
+
+// Incident returns the incident with the given ID.
+func (s *Scheduler) Incident(id string) (domain.Incident, error) {
+	return s.incidents.Get(id)
+}
+
+// AckIncident acknowledges an open incident on behalf of by, and the failure
+// of its target like Ack does.
+func (s *Scheduler) AckIncident(id, by string) (domain.Incident, error) {
+	inc, err := s.incidents.Ack(id, by)
+	if err != nil {
+		return domain.Incident{}, err
+	}
+
+	s.mu.Lock()
+	if status := s.states[inc.Target]; status == domain.StatusPending || status == domain.StatusUnhealthy {
+		s.acks[inc.Target] = *inc.Ack
+	}
+	s.mu.Unlock()
+
+	log.Printf("Incident %s (%s) acknowledged by %s", inc.ID, inc.Target, by)
+	return inc, nil
+}
+
+// NoteIncident adds a note by by to an incident's timeline.
+func (s *Scheduler) NoteIncident(id, by, message string) (domain.Incident, error) {
+	return s.incidents.Note(id, by, message)
+}
@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/robfig/cron/v3"
+)
+
+// plan describes when a target is checked: on a cron schedule or every
+// interval, restricted to its active windows.
+type plan struct {
+	cron    cron.Schedule // nil for interval targets
+	loc     *time.Location
+	windows []domain.Window
+}
+
+func newPlan(target domain.Target) (*plan, error) {
+	loc := time.Local
+	if target.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(target.TimeZone); err != nil {
+			return nil, fmt.Errorf("loading timezone: %w", err)
+		}
+	}
+
+	p := &plan{loc: loc, windows: target.ActiveWindows}
+	if target.Schedule != "" {
+		sched, err := cron.ParseStandard(target.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("parsing schedule: %w", err)
+		}
+		p.cron = sched
+	}
+	return p, nil
+}
+
+// next returns the first cron activation after t.
+func (p *plan) next(t time.Time) time.Time {
+	return p.cron.Next(t.In(p.loc))
+}
+
+// active reports whether checks may run at t.
+func (p *plan) active(t time.Time) bool {
+	if len(p.windows) == 0 {
+		return true
+	}
+	t = t.In(p.loc)
+	for _, w := range p.windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
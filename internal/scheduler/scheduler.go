@@ -2,125 +2,769 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"log"
+	"math/rand/v2"
+	"reflect"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/raha-io/joghd/internal/alerter"
 	"github.com/raha-io/joghd/internal/checker"
+	"github.com/raha-io/joghd/internal/clock"
 	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/incident"
+	"github.com/raha-io/joghd/internal/slo"
 )
 
 // Scheduler manages periodic health checks for multiple targets.
 type Scheduler struct {
-	checker checker.Checker
-	alerter alerter.Alerter
+	checker   checker.Checker
+	alerter   alerter.Alerter
+	slo       *slo.Tracker
+	incidents *incident.Store
+	clock     clock.Clock
+
+	// sem limits concurrent checks across all targets.
+	sem         chan struct{}
+	startSpread time.Duration
+	jitter      float64
+	random      func() float64
+
+	mu      sync.RWMutex
 	targets []domain.Target
+	states  map[string]domain.HealthStatus // target name -> health status
+	streaks map[string]int                 // target name -> consecutive failed checks
+	since   map[string]time.Time           // target name -> time of last status change
+	last    map[string]domain.CheckResult  // target name -> latest check result
+	acks    map[string]domain.Ack          // target name -> acknowledgement of a failure
+	deps    domain.DependencyGraph
+	// suppressed maps targets whose failure alert was suppressed to the
+	// dependency that was down at the time.
+	suppressed map[string]string
+	rechecks   map[string]chan recheck     // target name -> immediate check request
+	pings      map[string]chan domain.Ping // push target name -> received pings
+	burning    map[string]map[string]bool  // target name -> firing burn-rate rules
+
+	// loops and ctx are guarded by loopMu; ctx is set once Start is called.
+	loopMu sync.Mutex
+	ctx    context.Context
+	loops  map[string]*loop // target name -> running check loop
+	wg     sync.WaitGroup
+}
+
+// recheck asks a target loop for an immediate check. If reply is set, the
+// loop sends the result on it, or an error if it did not check the target.
+type recheck struct {
+	reply chan<- checkReply
+}
+
+// checkReply is the outcome of a recheck.
+type checkReply struct {
+	result domain.CheckResult
+	err    error
+}
+
+// done answers the recheck, if anyone is waiting for the answer.
+func (r recheck) done(result domain.CheckResult, err error) {
+	if r.reply != nil {
+		r.reply <- checkReply{result: result, err: err}
+	}
+}
+
+// loop is a running per-target check goroutine.
+type loop struct {
+	target domain.Target
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Option is a functional option for configuring the scheduler.
+type Option func(*Scheduler)
+
+// WithClock sets the clock driving check intervals. The checker keeps its own
+// clock, so tests usually pass the same clock to both.
+func WithClock(clk clock.Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = clk
+	}
+}
+
+// WithConcurrency limits how many checks run at once across all targets.
+// Checks beyond the limit wait for a slot.
+func WithConcurrency(n int) Option {
+	return func(s *Scheduler) {
+		if n > 0 {
+			s.sem = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithStartSpread delays each target's first check by a fixed offset within
+// d (capped at the target's interval), derived from the target name, so that
+// targets do not all fire at startup.
+func WithStartSpread(d time.Duration) Option {
+	return func(s *Scheduler) {
+		s.startSpread = d
+	}
+}
+
+// WithJitter shifts every check by a random amount of up to ±fraction of the
+// target's interval, so loops with equal intervals drift apart.
+func WithJitter(fraction float64) Option {
+	return func(s *Scheduler) {
+		s.jitter = fraction
+	}
+}
 
-	mu     sync.RWMutex
-	states map[string]domain.HealthStatus // target URL -> health status
+// WithIncidents sets the store that incidents are kept in. By default they
+// are kept in memory.
+func WithIncidents(store *incident.Store) Option {
+	return func(s *Scheduler) {
+		s.incidents = store
+	}
 }
 
 // New creates a new scheduler.
-func New(chk checker.Checker, alt alerter.Alerter, targets []domain.Target) *Scheduler {
+func New(chk checker.Checker, alt alerter.Alerter, targets []domain.Target, opts ...Option) *Scheduler {
 	states := make(map[string]domain.HealthStatus)
 	for _, t := range targets {
-		states[t.URL] = domain.StatusUnknown
+		states[t.Name] = domain.StatusUnknown
 	}
 
-	return &Scheduler{
+	s := &Scheduler{
 		checker: chk,
 		alerter: alt,
+		slo:     slo.NewTracker(),
+		clock:   clock.Real(),
+		random:  rand.Float64,
 		targets: targets,
 		states:  states,
+		streaks: make(map[string]int),
+		since:   make(map[string]time.Time),
+		last:    make(map[string]domain.CheckResult),
+		acks:    make(map[string]domain.Ack),
+		deps:    domain.NewDependencyGraph(targets),
+		burning: make(map[string]map[string]bool),
+		loops:   make(map[string]*loop),
+
+		incidents:  incident.New(),
+		suppressed: make(map[string]string),
+		rechecks:   make(map[string]chan recheck),
+		pings:      make(map[string]chan domain.Ping),
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 // Start begins the scheduling loop. Blocks until context is cancelled.
 func (s *Scheduler) Start(ctx context.Context) error {
-	var wg sync.WaitGroup
-
-	for _, target := range s.targets {
-		wg.Add(1)
-		go func(t domain.Target) {
-			defer wg.Done()
-			s.runTargetLoop(ctx, t)
-		}(target)
+	s.loopMu.Lock()
+	s.ctx = ctx
+	s.resolveUnmonitored()
+	for _, target := range s.Targets() {
+		s.startLoop(target)
 	}
+	log.Printf("Scheduler started for %d targets", len(s.loops))
+	s.loopMu.Unlock()
 
-	log.Printf("Scheduler started for %d targets", len(s.targets))
+	<-ctx.Done()
 
 	// Wait for all goroutines to finish
-	wg.Wait()
+	s.wg.Wait()
 
 	log.Println("Scheduler stopped")
 	return nil
 }
 
-func (s *Scheduler) runTargetLoop(ctx context.Context, target domain.Target) {
-	ticker := time.NewTicker(target.Interval)
-	defer ticker.Stop()
+// Reload replaces the monitored targets. Loops are started for added targets,
+// stopped for removed ones and restarted for changed ones. Health state is kept
+// for every target whose URL did not change, so a reload does not re-alert.
+func (s *Scheduler) Reload(targets []domain.Target) error {
+	s.loopMu.Lock()
+	defer s.loopMu.Unlock()
+
+	if s.ctx == nil {
+		return fmt.Errorf("scheduler not started")
+	}
+	if err := s.ctx.Err(); err != nil {
+		return fmt.Errorf("scheduler stopped: %w", err)
+	}
+
+	next := make(map[string]domain.Target, len(targets))
+	for _, t := range targets {
+		if _, dup := next[t.Name]; dup {
+			return fmt.Errorf("duplicate target name: %s", t.Name)
+		}
+		next[t.Name] = t
+	}
+
+	var added, removed, changed int
+	for name := range next {
+		if _, running := s.loops[name]; !running {
+			added++
+		}
+	}
 
-	// Run initial check immediately
-	s.checkAndAlert(ctx, target)
+	for name, l := range s.loops {
+		t, ok := next[name]
+		switch {
+		case !ok:
+			s.stopLoop(name)
+			s.forget(name)
+			removed++
+		case !reflect.DeepEqual(t, l.target):
+			s.stopLoop(name)
+			if t.URL != l.target.URL {
+				s.forget(name)
+			}
+			changed++
+		}
+	}
+
+	s.mu.Lock()
+	s.targets = targets
+	s.deps = domain.NewDependencyGraph(targets)
+	for _, t := range targets {
+		if _, ok := s.states[t.Name]; !ok {
+			s.states[t.Name] = domain.StatusUnknown
+		}
+	}
+	s.mu.Unlock()
 
+	for _, t := range targets {
+		if _, running := s.loops[t.Name]; !running {
+			s.startLoop(t)
+		}
+	}
+
+	log.Printf("Scheduler reloaded: %d targets (%d added, %d removed, %d changed)",
+		len(targets), added, removed, changed)
+	return nil
+}
+
+// Targets returns the currently monitored targets.
+func (s *Scheduler) Targets() []domain.Target {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.targets
+}
+
+// startLoop launches the check loop for a target. Must be called with loopMu held.
+func (s *Scheduler) startLoop(target domain.Target) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	l := &loop{target: target, cancel: cancel, done: make(chan struct{})}
+	s.loops[target.Name] = l
+
+	rechecks := make(chan recheck, 1)
+	var pings chan domain.Ping
+	s.mu.Lock()
+	s.rechecks[target.Name] = rechecks
+	if target.Push() {
+		pings = make(chan domain.Ping, pingBuffer)
+		s.pings[target.Name] = pings
+	}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer close(l.done)
+		if target.Push() {
+			s.runPushLoop(ctx, target, pings, rechecks)
+		} else {
+			s.runTargetLoop(ctx, target, rechecks)
+		}
+	}()
+}
+
+// stopLoop cancels a target's check loop and waits for it to exit. Must be
+// called with loopMu held.
+func (s *Scheduler) stopLoop(name string) {
+	l, ok := s.loops[name]
+	if !ok {
+		return
+	}
+	l.cancel()
+	<-l.done
+	delete(s.loops, name)
+
+	s.mu.Lock()
+	delete(s.rechecks, name)
+	delete(s.pings, name)
+	s.mu.Unlock()
+}
+
+// forget drops all health and SLO state kept for a target and resolves its
+// incident.
+func (s *Scheduler) forget(name string) {
+	s.mu.Lock()
+	delete(s.states, name)
+	delete(s.streaks, name)
+	delete(s.since, name)
+	delete(s.last, name)
+	delete(s.acks, name)
+	delete(s.suppressed, name)
+	delete(s.burning, name)
+	s.mu.Unlock()
+	s.slo.Forget(name)
+	s.incidents.Resolve(name, s.clock.Now(), "monitoring state reset by a config reload")
+}
+
+// runTargetLoop checks the target on its cron schedule, or every interval
+// starting after its start offset. Interval checks are scheduled against fixed
+// deadlines, so jitter does not accumulate and slow checks skip missed
+// deadlines instead of bunching up. While the target is pending or unhealthy
+// it is checked every failing interval instead, if set. Outside its active
+// windows the target is paused instead of checked. A request on rechecks runs
+// an extra check without changing the schedule, unless the target is paused.
+func (s *Scheduler) runTargetLoop(ctx context.Context, target domain.Target, rechecks <-chan recheck) {
+	p, err := newPlan(target)
+	if err != nil {
+		log.Printf("Target %s not scheduled: %v", target.Name, err)
+		return
+	}
+
+	now := s.clock.Now()
+	var next time.Time
+	if p.cron != nil {
+		next = p.next(now)
+	} else {
+		next = now.Add(s.startOffset(target))
+	}
+	timer := s.clock.NewTimer(next.Sub(now))
+	defer timer.Stop()
+
+	failing := false
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case req := <-rechecks:
+			if !p.active(s.clock.Now()) {
+				req.done(domain.CheckResult{}, fmt.Errorf("%w: %s is outside its active windows", ErrPaused, target.Name))
+				continue
+			}
+			if result, ok := s.checkAndAlert(ctx, target); ok {
+				req.done(result, nil)
+			} else {
+				req.done(result, ctx.Err())
+			}
+			continue
+		case <-timer.C():
+		}
+
+		if p.active(s.clock.Now()) {
 			s.checkAndAlert(ctx, target)
+		} else {
+			s.pause(target)
+		}
+
+		now := s.clock.Now()
+		if f := target.FailingInterval > 0 && s.failing(target.Name); f != failing {
+			failing = f
+			if failing {
+				log.Printf("Target %s failing, checking every %s", target.Name, target.FailingInterval)
+			} else {
+				log.Printf("Target %s back to its regular schedule", target.Name)
+			}
+			next = now
 		}
+
+		interval := target.Interval
+		switch {
+		case failing:
+			interval = target.FailingInterval
+		case p.cron != nil:
+			next = p.next(now)
+			timer.Reset(next.Sub(now))
+			continue
+		}
+		next = next.Add(interval)
+		for !next.After(now) {
+			next = next.Add(interval)
+		}
+		timer.Reset(next.Add(s.jitterFor(interval)).Sub(now))
 	}
 }
 
-func (s *Scheduler) checkAndAlert(ctx context.Context, target domain.Target) {
-	result := s.checker.Check(ctx, target)
+// failing reports whether the named target is pending or unhealthy.
+func (s *Scheduler) failing(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	status := s.states[name]
+	return status == domain.StatusPending || status == domain.StatusUnhealthy
+}
 
+// pause marks a target outside its active windows as paused. Paused targets
+// do not alert; the first check after the window reopens is treated like the
+// first check after startup.
+func (s *Scheduler) pause(target domain.Target) {
 	s.mu.Lock()
-	previousStatus := s.states[target.URL]
-	var currentStatus domain.HealthStatus
-	if result.Success {
-		currentStatus = domain.StatusHealthy
-	} else {
-		currentStatus = domain.StatusUnhealthy
+	previous := s.states[target.Name]
+	s.states[target.Name] = domain.StatusPaused
+	delete(s.streaks, target.Name)
+	delete(s.acks, target.Name)
+	if previous != domain.StatusPaused {
+		s.since[target.Name] = s.clock.Now()
 	}
-	s.states[target.URL] = currentStatus
 	s.mu.Unlock()
 
-	// Detect state transitions
-	statusChanged := previousStatus != currentStatus && previousStatus != domain.StatusUnknown
+	if previous != domain.StatusPaused {
+		log.Printf("Target %s paused (outside active windows)", target.Name)
+		s.incidents.Resolve(target.Name, s.clock.Now(), "target paused outside its active windows")
+	}
+}
+
+// startOffset returns the delay before a target's first check: a stable
+// position within the start spread derived from the target name.
+func (s *Scheduler) startOffset(target domain.Target) time.Duration {
+	spread := min(s.startSpread, target.Interval)
+	if spread <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(target.Name))
+	return time.Duration(h.Sum64() % uint64(spread))
+}
+
+// jitterFor returns a random shift of up to ±jitter × interval.
+func (s *Scheduler) jitterFor(interval time.Duration) time.Duration {
+	if s.jitter <= 0 {
+		return 0
+	}
+	return time.Duration((s.random()*2 - 1) * s.jitter * float64(interval))
+}
+
+// acquire waits for a check slot. It returns false if ctx is done first.
+func (s *Scheduler) acquire(ctx context.Context) bool {
+	if s.sem == nil {
+		return true
+	}
+	select {
+	case s.sem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *Scheduler) release() {
+	if s.sem != nil {
+		<-s.sem
+	}
+}
 
+// checkAndAlert checks the target and evaluates the result. It returns false
+// if ctx is done before a check slot is free.
+func (s *Scheduler) checkAndAlert(ctx context.Context, target domain.Target) (domain.CheckResult, bool) {
+	if !s.acquire(ctx) {
+		return domain.CheckResult{}, false
+	}
+	result := s.checker.Check(ctx, target)
+	s.release()
+
+	s.evaluate(ctx, target, result)
+	return result, true
+}
+
+// evaluate updates the target's health state from a check result and sends
+// the alerts the transition calls for.
+func (s *Scheduler) evaluate(ctx context.Context, target domain.Target, result domain.CheckResult) {
+	// A failing target is pending until it fails failure_threshold checks in
+	// a row, and only alerts once it turns unhealthy
+	s.mu.Lock()
+	previousStatus := s.states[target.Name]
+	currentStatus := domain.StatusHealthy
+	streak := 0
 	if !result.Success {
-		// Send failure alert on first failure or if still failing
+		streak = s.streaks[target.Name] + 1
+		currentStatus = domain.StatusPending
+		if streak >= target.FailureThreshold || previousStatus == domain.StatusUnhealthy {
+			currentStatus = domain.StatusUnhealthy
+		}
+	}
+	s.streaks[target.Name] = streak
+	s.states[target.Name] = currentStatus
+	s.last[target.Name] = result
+	if currentStatus != previousStatus {
+		s.since[target.Name] = result.Timestamp
+	}
+	if currentStatus == domain.StatusHealthy {
+		delete(s.acks, target.Name)
+	}
+	blocker := s.downDependency(target.Name)
+	_, wasSuppressed := s.suppressed[target.Name]
+	s.mu.Unlock()
+
+	if previousStatus == domain.StatusPaused {
+		log.Printf("Target %s resumed (active window open)", target.Name)
+	}
+
+	// Incidents follow the unhealthy state, whether or not alerts are sent
+	if currentStatus == domain.StatusUnhealthy {
+		opened := false
 		if previousStatus != domain.StatusUnhealthy {
+			msg := fmt.Sprintf("unhealthy after %d failed check(s): %s", streak, describe(result))
+			_, opened = s.incidents.Open(target.Name, result.Timestamp, msg)
+		}
+		if !opened {
+			s.incidents.Record(target.Name, domain.IncidentEvent{
+				Type: domain.IncidentCheck, Time: result.Timestamp, Message: describe(result),
+			})
+		}
+	}
+
+	switch {
+	case currentStatus == domain.StatusPending:
+		log.Printf("Target %s failing%s (%d/%d checks before alerting): %v",
+			target.Name, via(result), streak, target.FailureThreshold, result.Error)
+	case !result.Success && blocker != "" && (previousStatus != domain.StatusUnhealthy || wasSuppressed):
+		// Alerting is left to the dependency that is down, unless this target
+		// already alerted before the dependency went down
+		s.mu.Lock()
+		s.suppressed[target.Name] = blocker
+		s.mu.Unlock()
+		if !wasSuppressed {
+			log.Printf("Suppressed failure alert for %s: depends on %s, which is down", target.Name, blocker)
+			s.incidents.Record(target.Name, domain.IncidentEvent{
+				Type: domain.IncidentAlert, Time: s.clock.Now(),
+				Message: fmt.Sprintf("failure alert suppressed: depends on %s, which is down", blocker),
+			})
+		}
+	case !result.Success:
+		// Send failure alert when the target turns unhealthy, or when the
+		// dependency that suppressed its alert has recovered
+		if previousStatus != domain.StatusUnhealthy || wasSuppressed {
+			s.mu.Lock()
+			delete(s.suppressed, target.Name)
+			s.mu.Unlock()
+
 			alert := domain.NewFailureAlert(result)
-			if err := s.alerter.Send(ctx, alert); err != nil {
+			alert.Suppressed = s.suppressedBy(target.Name)
+			switch err := s.send(ctx, alert); {
+			case errors.Is(err, alerter.ErrSilenced):
+				log.Printf("Did not send failure alert for %s: %v", target.Name, err)
+			case err != nil:
 				log.Printf("Failed to send failure alert for %s: %v", target.Name, err)
-			} else {
-				log.Printf("Sent failure alert for %s", target.Name)
+			default:
+				log.Printf("Sent failure alert for %s%s: %v", target.Name, via(result), result.Error)
 			}
+		} else if target.Push() {
+			log.Printf("Target %s still unhealthy: %v", target.Name, result.Error)
 		} else {
-			log.Printf("Target %s still unhealthy (status: %d, expected: %d)",
-				target.Name, result.ActualStatus, target.ExpectedStatus)
+			log.Printf("Target %s still unhealthy%s (status: %d, expected: %d)",
+				target.Name, via(result), result.ActualStatus, target.ExpectedStatus)
 		}
-	} else if statusChanged && currentStatus == domain.StatusHealthy {
+	case wasSuppressed:
+		s.mu.Lock()
+		delete(s.suppressed, target.Name)
+		s.mu.Unlock()
+		log.Printf("Target %s recovered while its failure alert was suppressed", target.Name)
+	case previousStatus == domain.StatusUnhealthy:
 		// Send recovery alert
 		alert := domain.NewRecoveryAlert(result)
-		if err := s.alerter.Send(ctx, alert); err != nil {
+		alert.Suppressed = s.suppressedBy(target.Name)
+		switch err := s.send(ctx, alert); {
+		case errors.Is(err, alerter.ErrSilenced):
+			log.Printf("Did not send recovery alert for %s: %v", target.Name, err)
+		case err != nil:
 			log.Printf("Failed to send recovery alert for %s: %v", target.Name, err)
-		} else {
+		default:
 			log.Printf("Sent recovery alert for %s", target.Name)
 		}
-	} else if result.Success {
-		log.Printf("Target %s healthy (status: %d, latency: %s)",
-			target.Name, result.ActualStatus, result.Latency.Round(time.Millisecond))
+	case previousStatus == domain.StatusPending:
+		log.Printf("Target %s recovered before reaching its failure threshold", target.Name)
+	case target.Push():
+		log.Printf("Target %s healthy (ping received)", target.Name)
+	default:
+		log.Printf("Target %s healthy%s (status: %d, latency: %s, %s)",
+			target.Name, via(result), result.ActualStatus, result.Latency.Round(time.Millisecond), result.Timing)
+	}
+
+	if result.Success {
+		// Also resolves incidents restored from the state file whose target
+		// recovered while joghd was not running
+		s.incidents.Resolve(target.Name, s.clock.Now(), "recovered: "+describe(result))
+		s.recheckSuppressed(target.Name)
+	}
+
+	if target.SLO.Enabled() {
+		s.checkBurnRate(ctx, result)
+	}
+}
+
+// send sends alert as part of its target's open incident, if any, and records
+// the outcome in the incident's timeline.
+func (s *Scheduler) send(ctx context.Context, alert domain.Alert) error {
+	if inc, ok := s.incidents.Current(alert.Target.Name); ok {
+		alert.IncidentID = inc.ID
+	}
+	err := s.alerter.Send(ctx, alert)
+
+	kind := strings.ToLower(strings.ReplaceAll(alert.Type.String(), "_", " "))
+	msg := kind + " alert sent"
+	switch {
+	case errors.Is(err, alerter.ErrSilenced):
+		msg = kind + " alert not sent: " + err.Error()
+	case err != nil:
+		msg = kind + " alert failed: " + err.Error()
+	}
+	s.incidents.Record(alert.Target.Name, domain.IncidentEvent{Type: domain.IncidentAlert, Time: s.clock.Now(), Message: msg})
+	return err
+}
+
+// resolveUnmonitored resolves open incidents, restored from the state file, of
+// targets that are no longer configured.
+func (s *Scheduler) resolveUnmonitored() {
+	configured := make(map[string]bool)
+	for _, t := range s.Targets() {
+		configured[t.Name] = true
+	}
+	for _, inc := range s.incidents.List() {
+		if inc.Open() && !configured[inc.Target] {
+			s.incidents.Resolve(inc.Target, s.clock.Now(), "target is no longer monitored")
+		}
+	}
+}
+
+// downDependency returns the nearest dependency of the named target that is
+// pending or unhealthy, or "" if there is none. Must be called with mu held.
+func (s *Scheduler) downDependency(name string) string {
+	for _, dep := range s.deps.Ancestors(name) {
+		if status := s.states[dep]; status == domain.StatusPending || status == domain.StatusUnhealthy {
+			return dep
+		}
+	}
+	return ""
+}
+
+// suppressedBy lists the dependents of the named target whose failure alerts
+// it is holding back: those suppressed because of it and those still pending.
+func (s *Scheduler) suppressedBy(name string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []string
+	for _, child := range s.deps.Dependents(name) {
+		if s.suppressed[child] == name || s.states[child] == domain.StatusPending {
+			out = append(out, child)
+		}
 	}
+	return out
 }
 
-// GetStatus returns the current health status of a target.
-func (s *Scheduler) GetStatus(targetURL string) domain.HealthStatus {
+// recheckSuppressed asks every target whose alert the named target suppressed
+// to check again right away, so that it alerts if it is still failing now that
+// its dependency is healthy.
+func (s *Scheduler) recheckSuppressed(name string) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.states[targetURL]
+
+	for child, blocker := range s.suppressed {
+		if blocker != name {
+			continue
+		}
+		select {
+		case s.rechecks[child] <- recheck{}:
+		default:
+		}
+	}
+}
+
+// checkBurnRate records the result against the target's SLO and alerts when a
+// burn-rate rule starts firing. A rule alerts again only after it has cleared.
+func (s *Scheduler) checkBurnRate(ctx context.Context, result domain.CheckResult) {
+	target := result.Target
+	s.slo.Record(result)
+
+	now := result.Timestamp
+	firing := s.slo.Evaluate(target, now)
+
+	s.mu.Lock()
+	previous := s.burning[target.Name]
+	current := make(map[string]bool, len(firing))
+	var started []domain.BurnRate
+	for _, burn := range firing {
+		current[burn.Rule] = true
+		if !previous[burn.Rule] {
+			started = append(started, burn)
+		}
+	}
+	s.burning[target.Name] = current
+	s.mu.Unlock()
+
+	for rule := range previous {
+		if !current[rule] {
+			log.Printf("Burn rate for %s back within budget (%s)", target.Name, rule)
+		}
+	}
+
+	if len(started) == 0 {
+		return
+	}
+
+	status := s.slo.Status(target, now)
+	for _, burn := range started {
+		alert := domain.NewBurnRateAlert(result, status, burn)
+		switch err := s.send(ctx, alert); {
+		case errors.Is(err, alerter.ErrSilenced):
+			log.Printf("Did not send burn rate alert for %s: %v", target.Name, err)
+		case err != nil:
+			log.Printf("Failed to send burn rate alert for %s: %v", target.Name, err)
+		default:
+			log.Printf("Sent burn rate alert for %s (%s, %.1fx)", target.Name, burn.Rule, burn.LongRate)
+		}
+	}
+}
+
+// GetSLOStatus returns the SLO compliance of the named target. The second
+// return value is false if the target is unknown or declares no SLO.
+func (s *Scheduler) GetSLOStatus(targetName string) (domain.SLOStatus, bool) {
+	for _, t := range s.Targets() {
+		if t.Name == targetName && t.SLO.Enabled() {
+			return s.slo.Status(t, s.clock.Now()), true
+		}
+	}
+	return domain.SLOStatus{}, false
+}
+
+// GetStatus returns the current health status of a target by name.
+func (s *Scheduler) GetStatus(targetName string) domain.HealthStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	status, ok := s.states[targetName]
+	if !ok {
+		return domain.StatusUnknown
+	}
+	return status
+}
+
+// describe summarizes a check result for incident timelines.
+func describe(result domain.CheckResult) string {
+	switch {
+	case result.Success && result.Target.Push():
+		return "ping received"
+	case result.Success:
+		return fmt.Sprintf("status %d in %s", result.ActualStatus, result.Latency.Round(time.Millisecond))
+	case result.Error != nil:
+		return result.Error.Error()
+	default:
+		return fmt.Sprintf("status %d, expected %d", result.ActualStatus, result.Target.ExpectedStatus)
+	}
+}
+
+// via describes the proxy a check went through, for log lines.
+func via(result domain.CheckResult) string {
+	if result.Proxy == "" {
+		return ""
+	}
+	return " via proxy " + result.Proxy
 }
@@ -0,0 +1,338 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/checker"
+	"github.com/raha-io/joghd/internal/config"
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/fake"
+)
+
+const healthURL = "https://api.example.com/health"
+
+// harness runs a scheduler on a fake clock, with a scripted prober and a
+// recording alerter.
+type harness struct {
+	clock   *fake.Clock
+	prober  *fake.Prober
+	alerter *fake.Alerter
+	sched   *Scheduler
+	loops   int
+}
+
+// start starts a scheduler for target and stops it when the test ends. Checks
+// are not retried, so every check is exactly one request.
+func start(t *testing.T, target domain.Target, steps ...fake.Step) *harness {
+	t.Helper()
+	return startAll(t, []domain.Target{target}, map[string][]fake.Step{target.URL: steps})
+}
+
+// startAll is like start for several targets, with the responses for each
+// URL scripted in scripts.
+func startAll(t *testing.T, targets []domain.Target, scripts map[string][]fake.Step) *harness {
+	t.Helper()
+
+	h := &harness{
+		clock:   fake.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)),
+		alerter: fake.NewAlerter(),
+		loops:   len(targets),
+	}
+	h.prober = fake.NewProber(h.clock)
+	for url, steps := range scripts {
+		h.prober.Script(url, steps...)
+	}
+	chk := checker.New(
+		checker.WithHTTPClient(h.prober),
+		checker.WithClock(h.clock),
+		checker.WithRetryConfig(config.RetryConfig{MaxAttempts: 1}),
+	)
+	h.sched = New(chk, h.alerter, targets, WithClock(h.clock))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.sched.Start(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return h
+}
+
+// next waits for the checks in progress to finish and for every target loop
+// to schedule its following check.
+func (h *harness) next() {
+	h.clock.BlockUntil(h.loops)
+}
+
+func target(interval time.Duration, threshold int) domain.Target {
+	return domain.Target{
+		Name:             "API",
+		URL:              healthURL,
+		Method:           "GET",
+		ExpectedStatus:   200,
+		Timeout:          5 * time.Second,
+		Interval:         interval,
+		FailureThreshold: threshold,
+	}
+}
+
+func TestStateTransitionsWithFailureThreshold(t *testing.T) {
+	const interval = 30 * time.Second
+	tgt := target(interval, 2)
+
+	steps := append(fake.Repeat(3, fake.Status(500)), fake.Status(200))
+	want := []domain.HealthStatus{
+		domain.StatusPending,   // first failure, below the threshold
+		domain.StatusUnhealthy, // second failure reaches it and alerts
+		domain.StatusUnhealthy, // still failing, no new alert
+		domain.StatusHealthy,   // recovered
+	}
+	wantAlerts := [][]domain.AlertType{
+		nil,
+		{domain.AlertTypeFailure},
+		{domain.AlertTypeFailure},
+		{domain.AlertTypeFailure, domain.AlertTypeRecovery},
+	}
+
+	h := start(t, tgt, steps...)
+	if got := h.sched.GetStatus(tgt.Name); got != domain.StatusUnknown {
+		t.Fatalf("status before the first check = %s, want %s", got, domain.StatusUnknown)
+	}
+
+	for i := range want {
+		if i > 0 {
+			h.clock.Advance(interval)
+		}
+		h.next()
+
+		if got := h.sched.GetStatus(tgt.Name); got != want[i] {
+			t.Errorf("check %d: status = %s, want %s", i+1, got, want[i])
+		}
+		if got := h.alerter.Types(); !slices.Equal(got, wantAlerts[i]) {
+			t.Errorf("check %d: alerts = %v, want %v", i+1, got, wantAlerts[i])
+		}
+	}
+
+	alerts := h.alerter.Alerts()
+	if len(alerts) == 2 && (alerts[0].IncidentID == "" || alerts[0].IncidentID != alerts[1].IncidentID) {
+		t.Errorf("alerts belong to incidents %q and %q, want one shared incident",
+			alerts[0].IncidentID, alerts[1].IncidentID)
+	}
+}
+
+func TestPendingTargetRecoversWithoutAlerting(t *testing.T) {
+	const interval = 30 * time.Second
+	tgt := target(interval, 3)
+
+	h := start(t, tgt, fake.Status(500), fake.Status(500), fake.Status(200))
+	for i := range 3 {
+		if i > 0 {
+			h.clock.Advance(interval)
+		}
+		h.next()
+	}
+
+	if got := h.sched.GetStatus(tgt.Name); got != domain.StatusHealthy {
+		t.Errorf("status = %s, want %s", got, domain.StatusHealthy)
+	}
+	if got := h.alerter.Types(); len(got) != 0 {
+		t.Errorf("alerts = %v, want none", got)
+	}
+}
+
+func TestIntervalRunsOnFakeClock(t *testing.T) {
+	const interval = 30 * time.Second
+	tgt := target(interval, 1)
+
+	h := start(t, tgt, fake.Status(200))
+	h.next()
+	if got := h.prober.Calls(healthURL); got != 1 {
+		t.Fatalf("checks at start = %d, want 1", got)
+	}
+
+	for i := 2; i <= 4; i++ {
+		h.clock.Advance(interval - time.Second)
+		if got := h.prober.Calls(healthURL); got != i-1 {
+			t.Fatalf("checks 1s before check %d = %d, want %d", i, got, i-1)
+		}
+
+		h.clock.Advance(time.Second)
+		h.next()
+		if got := h.prober.Calls(healthURL); got != i {
+			t.Fatalf("checks after %s = %d, want %d", time.Duration(i-1)*interval, got, i)
+		}
+	}
+}
+
+// dependencyHarness starts a gateway checked every 50s and an API behind it
+// checked every 70s, so their checks only coincide at startup. Both are
+// healthy at startup.
+func dependencyHarness(t *testing.T, gateway, api []fake.Step) (*harness, func(at time.Duration)) {
+	t.Helper()
+
+	gw := target(50*time.Second, 1)
+	gw.Name, gw.URL = "Gateway", "https://gateway.example.com/health"
+	app := target(70*time.Second, 1)
+	app.DependsOn = []string{gw.Name}
+
+	h := startAll(t, []domain.Target{gw, app}, map[string][]fake.Step{
+		gw.URL:  append([]fake.Step{fake.Status(200)}, gateway...),
+		app.URL: append([]fake.Step{fake.Status(200)}, api...),
+	})
+	h.next()
+
+	// advance runs the clock to at, one check at a time
+	elapsed := time.Duration(0)
+	advance := func(at time.Duration) {
+		for elapsed < at {
+			step := min(50*time.Second-elapsed%(50*time.Second), 70*time.Second-elapsed%(70*time.Second), at-elapsed)
+			h.clock.Advance(step)
+			elapsed += step
+			h.next()
+		}
+	}
+	return h, advance
+}
+
+type alertOf struct {
+	Type   domain.AlertType
+	Target string
+}
+
+func alertsOf(a *fake.Alerter) []alertOf {
+	var out []alertOf
+	for _, alert := range a.Alerts() {
+		out = append(out, alertOf{alert.Type, alert.Target.Name})
+	}
+	return out
+}
+
+func TestDependentThatAlertedFirstStillRecovers(t *testing.T) {
+	// The API fails at 70s and alerts, the gateway fails at 100s and
+	// recovers at 200s, and the API recovers at 280s
+	h, advance := dependencyHarness(t,
+		[]fake.Step{fake.Status(200), fake.Status(500), fake.Status(500), fake.Status(200)},
+		[]fake.Step{fake.Status(500), fake.Status(500), fake.Status(500), fake.Status(200)},
+	)
+
+	advance(150 * time.Second)
+	want := []alertOf{
+		{domain.AlertTypeFailure, "API"},
+		{domain.AlertTypeFailure, "Gateway"},
+	}
+	if got := alertsOf(h.alerter); !slices.Equal(got, want) {
+		t.Fatalf("alerts while both are down = %v, want %v", got, want)
+	}
+
+	advance(280 * time.Second)
+	want = append(want,
+		alertOf{domain.AlertTypeRecovery, "Gateway"},
+		alertOf{domain.AlertTypeRecovery, "API"},
+	)
+	if got := alertsOf(h.alerter); !slices.Equal(got, want) {
+		t.Errorf("alerts = %v, want %v", got, want)
+	}
+	if got := h.sched.GetStatus("API"); got != domain.StatusHealthy {
+		t.Errorf("API status = %s, want %s", got, domain.StatusHealthy)
+	}
+}
+
+func TestDependentFailingAfterDependencyIsSuppressed(t *testing.T) {
+	// The gateway fails at 50s and recovers at 150s. The API fails at 70s,
+	// recovers at 140s while its alert is suppressed, then fails on its own
+	// at 210s and recovers at 280s
+	h, advance := dependencyHarness(t,
+		[]fake.Step{fake.Status(500), fake.Status(500), fake.Status(200)},
+		[]fake.Step{fake.Status(500), fake.Status(200), fake.Status(500), fake.Status(200)},
+	)
+
+	advance(140 * time.Second)
+	want := []alertOf{{domain.AlertTypeFailure, "Gateway"}}
+	if got := alertsOf(h.alerter); !slices.Equal(got, want) {
+		t.Fatalf("alerts while the gateway is down = %v, want %v", got, want)
+	}
+
+	advance(280 * time.Second)
+	want = append(want,
+		alertOf{domain.AlertTypeRecovery, "Gateway"},
+		alertOf{domain.AlertTypeFailure, "API"},
+		alertOf{domain.AlertTypeRecovery, "API"},
+	)
+	if got := alertsOf(h.alerter); !slices.Equal(got, want) {
+		t.Errorf("alerts = %v, want %v", got, want)
+	}
+}
+
+func TestCheckWaitsForScheduledCheck(t *testing.T) {
+	tgt := target(30*time.Second, 1)
+	h := start(t, tgt, fake.Slow(200, 10*time.Second), fake.Status(500))
+
+	// The scheduled check is waiting for its slow response
+	h.clock.BlockUntil(1)
+
+	type reply struct {
+		result domain.CheckResult
+		err    error
+	}
+	done := make(chan reply, 1)
+	go func() {
+		result, err := h.sched.Check(context.Background(), "api")
+		done <- reply{result, err}
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if got := h.prober.Calls(healthURL); got != 1 {
+		t.Fatalf("requests while the scheduled check runs = %d, want 1", got)
+	}
+
+	h.clock.Advance(10 * time.Second)
+	var r reply
+	select {
+	case r = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("on-demand check did not return")
+	}
+	if r.err != nil {
+		t.Fatalf("Check: %v", r.err)
+	}
+	if r.result.ActualStatus != 500 || r.result.Success {
+		t.Errorf("on-demand check got status %d (success %t), want the failing second response", r.result.ActualStatus, r.result.Success)
+	}
+	if got := h.sched.GetStatus(tgt.Name); got != domain.StatusUnhealthy {
+		t.Errorf("status = %s, want %s", got, domain.StatusUnhealthy)
+	}
+	if got := h.alerter.Types(); !slices.Equal(got, []domain.AlertType{domain.AlertTypeFailure}) {
+		t.Errorf("alerts = %v, want one failure", got)
+	}
+}
+
+func TestCheckSkipsPausedTarget(t *testing.T) {
+	// The harness clock starts at midnight, outside the window
+	tgt := target(30*time.Second, 1)
+	tgt.TimeZone = "UTC"
+	tgt.ActiveWindows = []domain.Window{{Start: "09:00", End: "17:00"}}
+	h := start(t, tgt, fake.Status(500))
+	h.next()
+
+	_, err := h.sched.Check(context.Background(), tgt.Name)
+	if !errors.Is(err, ErrPaused) {
+		t.Errorf("Check = %v, want ErrPaused", err)
+	}
+	if got := h.prober.Calls(healthURL); got != 0 {
+		t.Errorf("sent %d requests, want none", got)
+	}
+	if got := h.sched.GetStatus(tgt.Name); got != domain.StatusPaused {
+		t.Errorf("status = %s, want %s", got, domain.StatusPaused)
+	}
+	if got := h.alerter.Types(); len(got) != 0 {
+		t.Errorf("alerts = %v, want none", got)
+	}
+}
@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+var (
+	// ErrUnknownTarget is returned for names of no monitored target.
+	ErrUnknownTarget = errors.New("unknown target")
+	// ErrPaused is returned by Check for targets outside their active windows.
+	ErrPaused = errors.New("target is paused")
+)
+
+// Status returns a snapshot of every target's health, in config order.
+func (s *Scheduler) Status() []domain.TargetStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]domain.TargetStatus, 0, len(s.targets))
+	for _, t := range s.targets {
+		out = append(out, s.statusOf(t))
+	}
+	return out
+}
+
+// statusOf returns a snapshot of the target's health. Must be called with mu
+// held.
+func (s *Scheduler) statusOf(t domain.Target) domain.TargetStatus {
+	st := domain.TargetStatus{
+		Target: t,
+		Status: s.states[t.Name],
+		Since:  s.since[t.Name],
+	}
+	if last, ok := s.last[t.Name]; ok {
+		st.Last = &last
+	}
+	if ack, ok := s.acks[t.Name]; ok {
+		st.Ack = &ack
+	}
+	if inc, ok := s.incidents.Current(t.Name); ok {
+		st.Incident = inc.ID
+	}
+	return st
+}
+
+// Lookup returns the target with the given name, ignoring case.
+func (s *Scheduler) Lookup(name string) (domain.Target, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, t := range s.targets {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+	}
+	return domain.Target{}, fmt.Errorf("%w %q", ErrUnknownTarget, name)
+}
+
+// Check runs an immediate check of the named target outside its schedule and
+// evaluates it like a scheduled one, so it may alert. The check runs in the
+// target's loop, so it never overlaps a scheduled check of the same target.
+// Targets outside their active windows are not checked.
+func (s *Scheduler) Check(ctx context.Context, name string) (domain.CheckResult, error) {
+	target, err := s.Lookup(name)
+	if err != nil {
+		return domain.CheckResult{}, err
+	}
+
+	s.loopMu.Lock()
+	l, running := s.loops[target.Name]
+	s.mu.RLock()
+	rechecks := s.rechecks[target.Name]
+	s.mu.RUnlock()
+	s.loopMu.Unlock()
+
+	switch {
+	case !running:
+		return domain.CheckResult{}, fmt.Errorf("%s is not scheduled", target.Name)
+	case l.target.Push():
+		return domain.CheckResult{}, fmt.Errorf("%s is a push target and cannot be checked", target.Name)
+	}
+
+	replies := make(chan checkReply, 1)
+	select {
+	case rechecks <- recheck{reply: replies}:
+	case <-l.done:
+		return domain.CheckResult{}, fmt.Errorf("%s was reloaded, try again", target.Name)
+	case <-ctx.Done():
+		return domain.CheckResult{}, ctx.Err()
+	}
+
+	select {
+	case r := <-replies:
+		if r.err != nil {
+			return domain.CheckResult{}, r.err
+		}
+		log.Printf("Ran on-demand check of %s", target.Name)
+		return r.result, nil
+	case <-l.done:
+		return domain.CheckResult{}, fmt.Errorf("%s was reloaded, try again", target.Name)
+	case <-ctx.Done():
+		return domain.CheckResult{}, ctx.Err()
+	}
+}
+
+// Ack acknowledges the failure of the named target, and its open incident if
+// any, on behalf of by. The acknowledgement lasts until the target recovers.
+func (s *Scheduler) Ack(name, by string) (domain.TargetStatus, error) {
+	target, err := s.Lookup(name)
+	if err != nil {
+		return domain.TargetStatus{}, err
+	}
+
+	s.mu.Lock()
+	if status := s.states[target.Name]; status != domain.StatusPending && status != domain.StatusUnhealthy {
+		s.mu.Unlock()
+		return domain.TargetStatus{}, fmt.Errorf("%s is %s, there is nothing to acknowledge", target.Name, status)
+	}
+	s.acks[target.Name] = domain.Ack{By: by, At: s.clock.Now()}
+	s.mu.Unlock()
+
+	if inc, ok := s.incidents.Current(target.Name); ok {
+		if _, err := s.incidents.Ack(inc.ID, by); err != nil {
+			log.Printf("Failed to acknowledge incident %s: %v", inc.ID, err)
+		}
+	}
+
+	log.Printf("Target %s acknowledged by %s", target.Name, by)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.statusOf(target), nil
+}
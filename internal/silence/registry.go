@@ -0,0 +1,361 @@
+// Package silence mutes alerts during maintenance windows and ad-hoc
+// silences, and summarizes what was muted once they end.
+package silence
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/raha-io/joghd/internal/alerter"
+	"github.com/raha-io/joghd/internal/clock"
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// tick is how often Run checks for silences starting and ending.
+const tick = time.Second
+
+var (
+	// ErrNotFound is returned for unknown silence IDs.
+	ErrNotFound = errors.New("silence not found")
+	// ErrConfigured is returned when removing a silence defined in the config
+	// file, which would come back on the next reload.
+	ErrConfigured = errors.New("silence is defined in the config file")
+)
+
+// Registry holds maintenance windows from the config file and silences added
+// at runtime. It is an alerter.Alerter wrapping the one that actually sends:
+// alerts for targets matched by an active silence are recorded instead of
+// sent, and summarized when the silence ends.
+type Registry struct {
+	next  alerter.Alerter
+	clock clock.Clock
+
+	mu      sync.Mutex
+	entries []*entry // configured first, then ad hoc in creation order
+	retired []*entry // removed while active, summarized on the next tick
+}
+
+// entry is a silence and what it muted during its current activation.
+type entry struct {
+	silence    domain.Silence
+	configured bool
+	active     bool
+	started    time.Time
+	alerts     []domain.Alert
+}
+
+var _ alerter.Alerter = (*Registry)(nil)
+
+// Option is a functional option for configuring the registry.
+type Option func(*Registry)
+
+// WithClock sets the clock silences are evaluated against.
+func WithClock(clk clock.Clock) Option {
+	return func(r *Registry) {
+		r.clock = clk
+	}
+}
+
+// New creates a registry that sends unsilenced alerts and summaries to next.
+func New(next alerter.Alerter, opts ...Option) *Registry {
+	r := &Registry{
+		next:  next,
+		clock: clock.Real(),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Now returns the current time on the registry's clock, which decides
+// whether silences are active.
+func (r *Registry) Now() time.Time {
+	return r.clock.Now()
+}
+
+// Configure replaces the silences defined in the config file, which are
+// identified by name. A silence that is still configured keeps what it muted
+// so far; one removed while active is summarized.
+func (r *Registry) Configure(silences []domain.Silence) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := make(map[string]*entry)
+	var adhoc []*entry
+	for _, e := range r.entries {
+		if e.configured {
+			previous[e.silence.ID] = e
+		} else {
+			adhoc = append(adhoc, e)
+		}
+	}
+
+	entries := make([]*entry, 0, len(silences)+len(adhoc))
+	for _, s := range silences {
+		s.ID = s.Name
+		e, ok := previous[s.ID]
+		if ok {
+			delete(previous, s.ID)
+			e.silence = s
+		} else {
+			e = &entry{silence: s, configured: true}
+		}
+		entries = append(entries, e)
+	}
+	for _, e := range previous {
+		if e.active {
+			r.retired = append(r.retired, e)
+		}
+	}
+	r.entries = append(entries, adhoc...)
+}
+
+// Add registers an ad-hoc silence and returns it with its ID set.
+func (r *Registry) Add(s domain.Silence) (domain.Silence, error) {
+	if err := s.Validate(); err != nil {
+		return domain.Silence{}, err
+	}
+	if s.Expired(r.clock.Now()) {
+		return domain.Silence{}, fmt.Errorf("silence has already ended")
+	}
+	s.ID = newID()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, &entry{silence: s})
+
+	log.Printf("Added silence %s", describe(s))
+	return s, nil
+}
+
+// Remove deletes an ad-hoc silence. If it was active, its summary is sent on
+// the next tick of Run.
+func (r *Registry) Remove(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.IndexFunc(r.entries, func(e *entry) bool { return e.silence.ID == id })
+	if i < 0 {
+		return ErrNotFound
+	}
+	e := r.entries[i]
+	if e.configured {
+		return ErrConfigured
+	}
+
+	r.entries = slices.Delete(r.entries, i, i+1)
+	if e.active {
+		r.retired = append(r.retired, e)
+	}
+	log.Printf("Removed silence %s", e.silence.Label())
+	return nil
+}
+
+// List returns the silences that have not ended yet.
+func (r *Registry) List() []domain.Silence {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	var out []domain.Silence
+	for _, e := range r.entries {
+		if !e.silence.Expired(now) {
+			out = append(out, e.silence)
+		}
+	}
+	return out
+}
+
+// Silenced returns the first active silence matching target, if any.
+func (r *Registry) Silenced(target domain.Target) (domain.Silence, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	for _, e := range r.entries {
+		if e.silence.Active(now) && e.silence.Matches(target) {
+			return e.silence, true
+		}
+	}
+	return domain.Silence{}, false
+}
+
+// Send forwards alert to the wrapped alerter unless its target is matched by
+// an active silence, in which case it is recorded for that silence's summary.
+// The returned error then wraps alerter.ErrSilenced.
+func (r *Registry) Send(ctx context.Context, alert domain.Alert) error {
+	if name, ok := r.mute(alert); ok {
+		return fmt.Errorf("%w by %s", alerter.ErrSilenced, name)
+	}
+	return r.next.Send(ctx, alert)
+}
+
+// Name returns the name of the wrapped alerter.
+func (r *Registry) Name() string {
+	return r.next.Name()
+}
+
+// mute records alert with every active silence matching its target and
+// returns the name of the first one, if any.
+func (r *Registry) mute(alert domain.Alert) (string, bool) {
+	if alert.Target.Name == "" {
+		return "", false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	var names []string
+	for _, e := range r.entries {
+		if !e.silence.Active(now) || !e.silence.Matches(alert.Target) {
+			continue
+		}
+		if !e.active {
+			r.start(e, now)
+		}
+		e.alerts = append(e.alerts, alert)
+		names = append(names, e.silence.Label())
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+	return names[0], true
+}
+
+// Run tracks silences starting and ending until ctx is cancelled, sending a
+// summary through the wrapped alerter whenever one that muted alerts ends.
+func (r *Registry) Run(ctx context.Context) {
+	ticker := r.clock.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			for _, summary := range r.update() {
+				r.sendSummary(ctx, summary)
+			}
+		}
+	}
+}
+
+// update applies silences starting and ending since the last call, drops
+// expired ad-hoc silences and returns summaries of those that ended.
+func (r *Registry) update() []domain.SilenceSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	var ended []domain.SilenceSummary
+	for _, e := range r.retired {
+		ended = append(ended, r.end(e, now))
+	}
+	r.retired = nil
+
+	kept := r.entries[:0]
+	for _, e := range r.entries {
+		switch active := e.silence.Active(now); {
+		case active && !e.active:
+			r.start(e, now)
+		case !active && e.active:
+			ended = append(ended, r.end(e, now))
+		}
+		if e.configured || !e.silence.Expired(now) {
+			kept = append(kept, e)
+		}
+	}
+	clear(r.entries[len(kept):])
+	r.entries = kept
+
+	return ended
+}
+
+// start marks e as active. Must be called with mu held.
+func (r *Registry) start(e *entry, now time.Time) {
+	e.active = true
+	e.started = now
+	e.alerts = nil
+	log.Printf("Silence %s started", describe(e.silence))
+}
+
+// end marks e as inactive and summarizes what it muted. Must be called with
+// mu held.
+func (r *Registry) end(e *entry, now time.Time) domain.SilenceSummary {
+	summary := domain.SilenceSummary{
+		Silence: e.silence,
+		Started: e.started,
+		Ended:   now,
+		Alerts:  e.alerts,
+	}
+
+	// A target is presumably still down if its last muted alert was a failure
+	last := make(map[string]domain.AlertType)
+	var order []string
+	for _, alert := range e.alerts {
+		name := alert.Target.Name
+		if alert.Type != domain.AlertTypeFailure && alert.Type != domain.AlertTypeRecovery {
+			continue
+		}
+		if _, ok := last[name]; !ok {
+			order = append(order, name)
+		}
+		last[name] = alert.Type
+	}
+	for _, name := range order {
+		if last[name] == domain.AlertTypeFailure {
+			summary.Failing = append(summary.Failing, name)
+		}
+	}
+
+	e.active = false
+	e.alerts = nil
+	return summary
+}
+
+func (r *Registry) sendSummary(ctx context.Context, summary domain.SilenceSummary) {
+	name := summary.Silence.Label()
+	if len(summary.Alerts) == 0 {
+		log.Printf("Silence %s ended, no alerts were suppressed", name)
+		return
+	}
+
+	if err := r.next.Send(ctx, domain.NewSilenceSummaryAlert(summary)); err != nil {
+		log.Printf("Failed to send summary for silence %s: %v", name, err)
+	} else {
+		log.Printf("Silence %s ended, sent summary of %d suppressed alert(s)", name, len(summary.Alerts))
+	}
+}
+
+// describe returns the silence name and what it matches, for logging.
+func describe(s domain.Silence) string {
+	desc := s.Label()
+	if len(s.Targets) > 0 {
+		desc += fmt.Sprintf(" targets=%v", s.Targets)
+	}
+	if len(s.Labels) > 0 {
+		desc += fmt.Sprintf(" labels=%v", s.Labels)
+	}
+	if !s.Ends.IsZero() {
+		desc += " until " + s.Ends.Format(time.RFC3339)
+	}
+	return desc
+}
+
+// newID returns a random silence ID.
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,163 @@
+package silence
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/alerter"
+	"github.com/raha-io/joghd/internal/domain"
+	"github.com/raha-io/joghd/internal/fake"
+)
+
+var epoch = time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+func alertFor(typ domain.AlertType, name string, labels map[string]string) domain.Alert {
+	return domain.Alert{Type: typ, Target: domain.Target{Name: name, Labels: labels}}
+}
+
+func TestSilenced(t *testing.T) {
+	clk := fake.NewClock(epoch)
+	r := New(fake.NewAlerter(), WithClock(clk))
+	r.Configure([]domain.Silence{
+		{Name: "payments", Labels: map[string]string{"team": "payments"}, Ends: epoch.Add(time.Hour)},
+		{Name: "eu api", Targets: []string{"api-eu-*"}, Labels: map[string]string{"tier": "1"}, Ends: epoch.Add(time.Hour)},
+		{Name: "later", Targets: []string{"db"}, Starts: epoch.Add(time.Hour), Ends: epoch.Add(2 * time.Hour)},
+	})
+
+	tests := []struct {
+		target domain.Target
+		want   string
+	}{
+		{domain.Target{Name: "billing", Labels: map[string]string{"team": "payments"}}, "payments"},
+		{domain.Target{Name: "billing", Labels: map[string]string{"team": "search"}}, ""},
+		{domain.Target{Name: "api-eu-1", Labels: map[string]string{"tier": "1"}}, "eu api"},
+		{domain.Target{Name: "api-eu-1", Labels: map[string]string{"tier": "2"}}, ""},
+		{domain.Target{Name: "api-us-1", Labels: map[string]string{"tier": "1"}}, ""},
+		{domain.Target{Name: "db"}, ""},
+	}
+	for _, tt := range tests {
+		sil, ok := r.Silenced(tt.target)
+		if got := sil.Name; ok != (tt.want != "") || got != tt.want {
+			t.Errorf("Silenced(%s %v) = %q, %t, want %q", tt.target.Name, tt.target.Labels, got, ok, tt.want)
+		}
+	}
+
+	clk.Advance(time.Hour)
+	if sil, ok := r.Silenced(domain.Target{Name: "db"}); !ok || sil.Name != "later" {
+		t.Errorf("db not silenced once the later silence started")
+	}
+	if _, ok := r.Silenced(domain.Target{Name: "billing", Labels: map[string]string{"team": "payments"}}); ok {
+		t.Errorf("billing still silenced after its silence ended")
+	}
+}
+
+func TestSendMutesMatchingAlerts(t *testing.T) {
+	next := fake.NewAlerter()
+	r := New(next, WithClock(fake.NewClock(epoch)))
+	if _, err := r.Add(domain.Silence{Targets: []string{"api"}, Ends: epoch.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Send(context.Background(), alertFor(domain.AlertTypeFailure, "api", nil)); !errors.Is(err, alerter.ErrSilenced) {
+		t.Errorf("Send for a silenced target = %v, want ErrSilenced", err)
+	}
+	if err := r.Send(context.Background(), alertFor(domain.AlertTypeFailure, "db", nil)); err != nil {
+		t.Errorf("Send for another target = %v", err)
+	}
+	if got := next.Alerts(); len(got) != 1 || got[0].Target.Name != "db" {
+		t.Errorf("forwarded %v, want only the db alert", got)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	clk := fake.NewClock(epoch)
+	r := New(fake.NewAlerter(), WithClock(clk))
+	r.Configure([]domain.Silence{{Name: "configured", Targets: []string{"db"}, Ends: epoch.Add(time.Hour)}})
+
+	if _, err := r.Add(domain.Silence{Targets: []string{"api"}, Ends: epoch}); err == nil {
+		t.Error("added a silence that has already ended")
+	}
+	adhoc, err := r.Add(domain.Silence{Targets: []string{"api"}, Ends: epoch.Add(30 * time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(r.List()); got != 2 {
+		t.Fatalf("listed %d silences, want 2", got)
+	}
+
+	clk.Advance(30 * time.Minute)
+	if got := r.List(); len(got) != 1 || got[0].Name != "configured" {
+		t.Errorf("listed %v after the ad-hoc silence ended, want only the configured one", got)
+	}
+	r.update()
+	if err := r.Remove(adhoc.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Remove of the expired silence = %v, want ErrNotFound", err)
+	}
+
+	clk.Advance(30 * time.Minute)
+	r.update()
+	if got := len(r.List()); got != 0 {
+		t.Errorf("listed %d silences after all ended, want 0", got)
+	}
+	if err := r.Remove("configured"); !errors.Is(err, ErrConfigured) {
+		t.Errorf("Remove of the configured silence = %v, want ErrConfigured", err)
+	}
+}
+
+func TestSummaryWhenSilenceEnds(t *testing.T) {
+	clk := fake.NewClock(epoch)
+	next := fake.NewAlerter()
+	r := New(next, WithClock(clk))
+	r.Configure([]domain.Silence{{Name: "deploy", Targets: []string{"api-*"}, Ends: epoch.Add(10 * time.Minute)}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	muted := []domain.Alert{
+		alertFor(domain.AlertTypeFailure, "api-eu", nil),
+		alertFor(domain.AlertTypeFailure, "api-us", nil),
+		alertFor(domain.AlertTypeRecovery, "api-eu", nil),
+	}
+	for _, alert := range muted {
+		if err := r.Send(ctx, alert); !errors.Is(err, alerter.ErrSilenced) {
+			t.Fatalf("Send = %v, want ErrSilenced", err)
+		}
+	}
+	if got := next.Alerts(); len(got) != 0 {
+		t.Fatalf("forwarded %d alerts during the silence, want none", len(got))
+	}
+
+	clk.BlockUntil(1)
+	clk.Advance(10 * time.Minute)
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer waitCancel()
+	alerts, err := next.Wait(waitCtx, 1)
+	if err != nil {
+		t.Fatalf("no summary after the silence ended: %v", err)
+	}
+	summary := alerts[0].Summary
+	if alerts[0].Type != domain.AlertTypeSilenceSummary || summary == nil {
+		t.Fatalf("got %s alert, want a silence summary", alerts[0].Type)
+	}
+	if len(summary.Alerts) != len(muted) {
+		t.Errorf("summary lists %d alerts, want %d", len(summary.Alerts), len(muted))
+	}
+	if !slices.Equal(summary.Failing, []string{"api-us"}) {
+		t.Errorf("summary failing = %v, want [api-us]", summary.Failing)
+	}
+	if !summary.Started.Equal(epoch) || summary.Ended.Before(epoch.Add(10*time.Minute)) {
+		t.Errorf("summary covers %s to %s, want %s to the end of the silence", summary.Started, summary.Ended, epoch)
+	}
+}
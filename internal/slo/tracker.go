@@ -0,0 +1,220 @@
+package slo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+// resolution is the width of a history bucket. Check results are aggregated
+// per bucket so a 30 day window stays small in memory.
+const resolution = time.Minute
+
+// Rule is a multi-window burn-rate alerting rule. It fires when the burn rate
+// over both the long and the short window exceeds the threshold.
+type Rule struct {
+	Name        string
+	LongWindow  time.Duration
+	ShortWindow time.Duration
+	Threshold   float64
+	Severity    domain.Severity
+}
+
+// DefaultRules are the fast and slow burn rules recommended for a 30 day window.
+var DefaultRules = []Rule{
+	{Name: "fast burn", LongWindow: time.Hour, ShortWindow: 5 * time.Minute, Threshold: 14.4, Severity: domain.SeverityCritical},
+	{Name: "slow burn", LongWindow: 6 * time.Hour, ShortWindow: 30 * time.Minute, Threshold: 6, Severity: domain.SeverityWarning},
+}
+
+// bucket aggregates the check results of one resolution interval. Besides
+// the counts it sums the time the results cover, which availability and burn
+// rates are computed from.
+type bucket struct {
+	start    time.Time
+	total    int
+	failures int
+	covered  time.Duration
+	down     time.Duration
+}
+
+// Tracker keeps per-target check history and evaluates SLO compliance.
+type Tracker struct {
+	rules []Rule
+
+	mu      sync.Mutex
+	history map[string][]bucket  // target name -> buckets, oldest first
+	last    map[string]time.Time // target name -> time of the last result
+}
+
+// NewTracker creates a tracker evaluating the given rules, or DefaultRules if none are given.
+func NewTracker(rules ...Rule) *Tracker {
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+	return &Tracker{
+		rules:   rules,
+		history: make(map[string][]bucket),
+		last:    make(map[string]time.Time),
+	}
+}
+
+// Record adds a check result to the target's history. A result covers the
+// time since the previous one, up to the target's interval, so checks run more
+// often, e.g. every failing_interval or on demand, do not outweigh the
+// regular ones.
+func (t *Tracker) Record(result domain.CheckResult) {
+	target := result.Target
+	if !target.SLO.Enabled() {
+		return
+	}
+
+	start := result.Timestamp.Truncate(resolution)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	covered := coverage(target)
+	if last, ok := t.last[target.Name]; ok {
+		if d := result.Timestamp.Sub(last); d >= 0 && d < covered {
+			covered = d
+		}
+	}
+	t.last[target.Name] = result.Timestamp
+
+	buckets := t.history[target.Name]
+	if n := len(buckets); n == 0 || buckets[n-1].start.Before(start) {
+		buckets = append(buckets, bucket{start: start})
+	}
+	b := &buckets[len(buckets)-1]
+	b.total++
+	b.covered += covered
+	if !result.Success {
+		b.failures++
+		b.down += covered
+	}
+
+	// Drop buckets that fell out of the SLO window
+	cutoff := start.Add(-t.retention(target.SLO))
+	drop := 0
+	for drop < len(buckets) && buckets[drop].start.Before(cutoff) {
+		drop++
+	}
+	t.history[target.Name] = buckets[drop:]
+}
+
+// Status returns the availability and remaining error budget over the SLO window.
+func (t *Tracker) Status(target domain.Target, now time.Time) domain.SLOStatus {
+	status := domain.SLOStatus{
+		Objective:       target.SLO.Objective,
+		Window:          target.SLO.Window,
+		Availability:    1,
+		BudgetRemaining: 1,
+	}
+
+	t.mu.Lock()
+	total, failures, covered, down := t.count(target.Name, now, target.SLO.Window)
+	t.mu.Unlock()
+
+	status.Checks = total
+	status.Failures = failures
+	if covered <= 0 {
+		return status
+	}
+
+	failRatio := float64(down) / float64(covered)
+	status.Availability = 1 - failRatio
+	if budget := target.SLO.ErrorBudget(); budget > 0 {
+		status.BudgetRemaining = 1 - failRatio/budget
+	}
+	return status
+}
+
+// Evaluate returns the burn-rate rules currently firing for the target.
+func (t *Tracker) Evaluate(target domain.Target, now time.Time) []domain.BurnRate {
+	budget := target.SLO.ErrorBudget()
+	if !target.SLO.Enabled() || budget <= 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var firing []domain.BurnRate
+	for _, rule := range t.rules {
+		longRate := t.burnRate(target.Name, now, rule.LongWindow, budget)
+		shortRate := t.burnRate(target.Name, now, rule.ShortWindow, budget)
+		if longRate >= rule.Threshold && shortRate >= rule.Threshold {
+			firing = append(firing, domain.BurnRate{
+				Rule:        rule.Name,
+				LongWindow:  rule.LongWindow,
+				ShortWindow: rule.ShortWindow,
+				Threshold:   rule.Threshold,
+				LongRate:    longRate,
+				ShortRate:   shortRate,
+				Severity:    rule.Severity,
+			})
+		}
+	}
+	return firing
+}
+
+// Forget drops the history of a target.
+func (t *Tracker) Forget(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.history, name)
+	delete(t.last, name)
+}
+
+// burnRate returns how many times faster than allowed the budget is consumed
+// over the window. Must be called with mu held.
+func (t *Tracker) burnRate(name string, now time.Time, window time.Duration, budget float64) float64 {
+	_, _, covered, down := t.count(name, now, window)
+	if covered <= 0 {
+		return 0
+	}
+	return float64(down) / float64(covered) / budget
+}
+
+// count sums checks and failures, and the time they cover, within the window
+// ending at now. Must be called with mu held.
+func (t *Tracker) count(name string, now time.Time, window time.Duration) (total, failures int, covered, down time.Duration) {
+	cutoff := now.Add(-window)
+	buckets := t.history[name]
+	for i := len(buckets) - 1; i >= 0; i-- {
+		if buckets[i].start.Add(resolution).Before(cutoff) {
+			break
+		}
+		total += buckets[i].total
+		failures += buckets[i].failures
+		covered += buckets[i].covered
+		down += buckets[i].down
+	}
+	return total, failures, covered, down
+}
+
+// coverage returns the most time one result of the target may cover: its
+// interval or push period, or the bucket resolution for cron schedules.
+func coverage(target domain.Target) time.Duration {
+	switch {
+	case target.Interval > 0:
+		return target.Interval
+	case target.Period > 0:
+		return target.Period
+	default:
+		return resolution
+	}
+}
+
+// retention returns how much history must be kept to evaluate the SLO window
+// and every burn-rate rule.
+func (t *Tracker) retention(s domain.SLO) time.Duration {
+	keep := s.Window
+	for _, rule := range t.rules {
+		if rule.LongWindow > keep {
+			keep = rule.LongWindow
+		}
+	}
+	return keep
+}
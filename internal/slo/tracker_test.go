@@ -0,0 +1,174 @@
+package slo
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raha-io/joghd/internal/domain"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func sloTarget(interval time.Duration) domain.Target {
+	return domain.Target{
+		Name:     "API",
+		Interval: interval,
+		SLO:      domain.SLO{Objective: 99.9, Window: 30 * 24 * time.Hour},
+	}
+}
+
+// record records one check of target every interval from start until end,
+// failing where down returns true, and returns end.
+func record(tr *Tracker, target domain.Target, start, end time.Time, interval time.Duration, down func(time.Time) bool) time.Time {
+	for at := start; at.Before(end); at = at.Add(interval) {
+		tr.Record(domain.CheckResult{Target: target, Timestamp: at, Success: !down(at)})
+	}
+	return end
+}
+
+func never(time.Time) bool  { return false }
+func always(time.Time) bool { return true }
+
+func firingRules(burns []domain.BurnRate) []string {
+	var names []string
+	for _, b := range burns {
+		names = append(names, b.Rule)
+	}
+	return names
+}
+
+func TestStatusWithoutHistory(t *testing.T) {
+	tr := NewTracker()
+	st := tr.Status(sloTarget(time.Minute), epoch)
+	if st.Availability != 1 || st.BudgetRemaining != 1 || st.Checks != 0 {
+		t.Errorf("Status = %+v, want full availability and budget", st)
+	}
+}
+
+func TestStatusAvailability(t *testing.T) {
+	tr := NewTracker()
+	target := sloTarget(time.Minute)
+
+	// 1000 minutes, one of them down
+	failedAt := epoch.Add(500 * time.Minute)
+	now := record(tr, target, epoch, epoch.Add(1000*time.Minute), time.Minute, func(at time.Time) bool {
+		return at.Equal(failedAt)
+	})
+
+	st := tr.Status(target, now)
+	if st.Checks != 1000 || st.Failures != 1 {
+		t.Errorf("Checks, Failures = %d, %d, want 1000, 1", st.Checks, st.Failures)
+	}
+	if math.Abs(st.Availability-0.999) > 1e-9 {
+		t.Errorf("Availability = %f, want 0.999", st.Availability)
+	}
+	if math.Abs(st.BudgetRemaining) > 1e-6 {
+		t.Errorf("BudgetRemaining = %f, want 0 (budget used up)", st.BudgetRemaining)
+	}
+}
+
+func TestFailingIntervalDoesNotInflateFailures(t *testing.T) {
+	tr := NewTracker()
+	target := sloTarget(5 * time.Minute)
+	target.FailingInterval = 5 * time.Second
+
+	// An hour up at a 5m interval, a 5m outage checked every 5s, then up
+	now := record(tr, target, epoch, epoch.Add(time.Hour), 5*time.Minute, never)
+	now = record(tr, target, now, now.Add(5*time.Minute), 5*time.Second, always)
+	now = record(tr, target, now, now.Add(time.Hour), 5*time.Minute, never)
+
+	st := tr.Status(target, now)
+	if st.Failures != 60 || st.Checks != 84 {
+		t.Errorf("Checks, Failures = %d, %d, want 84, 60", st.Checks, st.Failures)
+	}
+	// The first failure covers the 5m since the last success, the other 59
+	// cover 5s each: about 10 of 125 minutes down, not 60 of 84 checks
+	want := 1 - (5*time.Minute+59*5*time.Second).Minutes()/(125*time.Minute).Minutes()
+	if math.Abs(st.Availability-want) > 1e-9 {
+		t.Errorf("Availability = %f, want %f", st.Availability, want)
+	}
+}
+
+func TestOnDemandChecksCoverOnlyTheirGap(t *testing.T) {
+	tr := NewTracker()
+	target := sloTarget(5 * time.Minute)
+
+	now := record(tr, target, epoch, epoch.Add(time.Hour), 5*time.Minute, never)
+	// A failed on-demand check 10s after the last scheduled one
+	tr.Record(domain.CheckResult{Target: target, Timestamp: now.Add(-5*time.Minute + 10*time.Second)})
+
+	st := tr.Status(target, now)
+	want := 1 - (10*time.Second).Seconds()/(time.Hour+10*time.Second).Seconds()
+	if math.Abs(st.Availability-want) > 1e-9 {
+		t.Errorf("Availability = %f, want %f", st.Availability, want)
+	}
+}
+
+func TestEvaluateDefaultRules(t *testing.T) {
+	minute := time.Minute
+	tests := []struct {
+		name string
+		down func(start time.Time) func(time.Time) bool
+		want []string
+	}{
+		{
+			name: "healthy",
+			down: func(time.Time) func(time.Time) bool { return never },
+			want: nil,
+		},
+		{
+			// 1 of the last 60 minutes (16.7x) and of the last 5 (200x), but
+			// 1 of 360 (2.8x) over 6h
+			name: "fast burn",
+			down: func(end time.Time) func(time.Time) bool {
+				return func(at time.Time) bool { return at.Equal(end.Add(-2 * minute)) }
+			},
+			want: []string{"fast burn"},
+		},
+		{
+			// 3 of 360 minutes (8.3x) and 1 of the last 30 (33x), but none in
+			// the last 5
+			name: "slow burn",
+			down: func(end time.Time) func(time.Time) bool {
+				return func(at time.Time) bool {
+					for _, ago := range []time.Duration{20, 100, 200} {
+						if at.Equal(end.Add(-ago * minute)) {
+							return true
+						}
+					}
+					return false
+				}
+			},
+			want: []string{"slow burn"},
+		},
+		{
+			name: "outage",
+			down: func(end time.Time) func(time.Time) bool {
+				return func(at time.Time) bool { return !at.Before(end.Add(-30 * minute)) }
+			},
+			want: []string{"fast burn", "slow burn"},
+		},
+	}
+	for _, tt := range tests {
+		tr := NewTracker()
+		target := sloTarget(minute)
+		end := epoch.Add(6 * time.Hour)
+		now := record(tr, target, epoch, end, minute, tt.down(end))
+
+		if got := firingRules(tr.Evaluate(target, now)); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: firing %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateWithoutSLO(t *testing.T) {
+	tr := NewTracker()
+	target := domain.Target{Name: "API", Interval: time.Minute}
+	now := record(tr, target, epoch, epoch.Add(time.Hour), time.Minute, always)
+
+	if got := tr.Evaluate(target, now); got != nil {
+		t.Errorf("Evaluate = %v, want nil for a target without an SLO", got)
+	}
+}
@@ -0,0 +1,44 @@
+package telegram
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// MaxErrorLength bounds how much of an error message an alert includes.
+const MaxErrorLength = 1024
+
+// funcs are the functions available in alert templates besides the built-in
+// ones.
+var funcs = template.FuncMap{
+	"join": strings.Join,
+	"truncate": func(n int, v any) string {
+		switch v := v.(type) {
+		case nil:
+			return ""
+		case error:
+			return Truncate(v.Error(), n)
+		case string:
+			return Truncate(v, n)
+		default:
+			return Truncate(fmt.Sprint(v), n)
+		}
+	},
+	"round": func(d time.Duration) time.Duration {
+		return d.Round(time.Millisecond)
+	},
+	"time": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04:05 MST")
+	},
+}
+
+// ParseTemplate parses an alert message template. Templates are executed
+// with the domain.Alert as data and produce parse_mode HTML, with values
+// escaped automatically. Besides the built-in functions they can use join,
+// truncate (e.g. {{truncate 200 .Result.Error}}), round for durations and
+// time for timestamps.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(funcs).Parse(text)
+}
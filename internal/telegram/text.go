@@ -0,0 +1,217 @@
+// Package telegram holds the message text rules of the Telegram Bot API
+// shared by the alerter and the bot: HTML escaping, length limits and alert
+// templates.
+package telegram
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MaxMessageLength is the longest message text the Bot API accepts. It is
+// counted in UTF-16 code units, so most emoji count twice.
+const MaxMessageLength = 4096
+
+// escaper escapes the characters that are special in parse_mode HTML.
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Escape escapes s for a message sent with parse_mode HTML.
+func Escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// Length returns the length of s as counted by the Bot API.
+func Length(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// Truncate shortens s to at most n characters as counted by the Bot API,
+// ending it with "…" if anything was cut.
+func Truncate(s string, n int) string {
+	if Length(s) <= n {
+		return s
+	}
+	return cut(s, n-1) + "…"
+}
+
+// Split breaks a parse_mode HTML text into messages of at most
+// MaxMessageLength, at line breaks where possible. Lines longer than the limit
+// are cut, but never inside a tag or an entity such as "&amp;". Tags open at
+// a cut are closed at the end of the part and reopened at the start of the
+// next, so every part is valid HTML on its own.
+func Split(text string) []string {
+	return split(text, true)
+}
+
+// SplitPlain is Split for messages sent without a parse_mode, which have no
+// tags or entities to keep intact.
+func SplitPlain(text string) []string {
+	return split(text, false)
+}
+
+func split(text string, html bool) []string {
+	if Length(text) <= MaxMessageLength {
+		return []string{text}
+	}
+
+	sp := splitter{html: html}
+	for _, line := range strings.Split(text, "\n") {
+		if sp.started() {
+			if sp.fits("\n" + line) {
+				sp.write("\n" + line)
+				continue
+			}
+			sp.flush()
+		}
+		if sp.fits(line) {
+			sp.write(line)
+			continue
+		}
+
+		// The line does not fit in a part of its own, cut it where it must be
+		for rest := line; rest != ""; {
+			unit := rest[:sp.unitLen(rest)]
+			if sp.started() && !sp.fits(unit) {
+				sp.flush()
+			}
+			sp.write(unit)
+			rest = rest[len(unit):]
+		}
+	}
+	return sp.finish()
+}
+
+// splitter builds the parts of a split message.
+type splitter struct {
+	html  bool
+	parts []string
+	sb    strings.Builder
+	size  int      // length of sb as counted by the Bot API
+	start int      // offset in sb after the tags reopened from the last part
+	open  []string // opening tags not yet closed, outermost first
+}
+
+// started reports whether anything but reopened tags was written to the
+// current part.
+func (sp *splitter) started() bool {
+	return sp.sb.Len() > sp.start
+}
+
+// fits reports whether s can be added to the current part, leaving room to
+// close the tags still open after it.
+func (sp *splitter) fits(s string) bool {
+	open := sp.open
+	for rest := s; rest != ""; {
+		n := sp.unitLen(rest)
+		open = sp.track(open, rest[:n])
+		rest = rest[n:]
+	}
+	return sp.size+Length(s)+Length(closeTags(open)) <= MaxMessageLength
+}
+
+// write adds s to the current part.
+func (sp *splitter) write(s string) {
+	for rest := s; rest != ""; {
+		n := sp.unitLen(rest)
+		sp.open = sp.track(sp.open, rest[:n])
+		rest = rest[n:]
+	}
+	sp.sb.WriteString(s)
+	sp.size += Length(s)
+}
+
+// flush ends the current part, closing its open tags, and starts the next
+// one with them reopened.
+func (sp *splitter) flush() {
+	sp.sb.WriteString(closeTags(sp.open))
+	sp.parts = append(sp.parts, sp.sb.String())
+
+	reopen := strings.Join(sp.open, "")
+	sp.sb.Reset()
+	sp.sb.WriteString(reopen)
+	sp.size = Length(reopen)
+	sp.start = sp.sb.Len()
+}
+
+// finish ends the last part, unless it is blank, and returns the parts.
+func (sp *splitter) finish() []string {
+	if strings.TrimSpace(sp.sb.String()[sp.start:]) != "" {
+		sp.sb.WriteString(closeTags(sp.open))
+		sp.parts = append(sp.parts, sp.sb.String())
+	}
+	return sp.parts
+}
+
+// unitLen returns the length in bytes of the first unit of s that must not
+// be cut: a tag, an entity or a single character.
+func (sp *splitter) unitLen(s string) int {
+	if sp.html {
+		switch s[0] {
+		case '<':
+			if i := strings.IndexByte(s, '>'); i >= 0 {
+				return i + 1
+			}
+		case '&':
+			// The longest entity Telegram supports is "&#x10FFFF;"
+			if i := strings.IndexByte(s, ';'); i > 0 && i < 10 {
+				return i + 1
+			}
+		}
+	}
+	_, n := utf8.DecodeRuneInString(s)
+	return n
+}
+
+// track returns the tags open after unit, given the tags open before it. It
+// does not modify open.
+func (sp *splitter) track(open []string, unit string) []string {
+	if !sp.html || !strings.HasPrefix(unit, "<") {
+		return open
+	}
+	name := tagName(unit)
+	if !strings.HasPrefix(unit, "</") {
+		return append(slices.Clip(open), unit)
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		if tagName(open[i]) == name {
+			return open[:i:i]
+		}
+	}
+	return open
+}
+
+// tagName returns the lowercased name of an opening or closing tag.
+func tagName(tag string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(tag, "<"), "/")
+	if i := strings.IndexAny(name, " \t\n>"); i >= 0 {
+		name = name[:i]
+	}
+	return strings.ToLower(name)
+}
+
+// closeTags returns the closing tags for open, innermost first.
+func closeTags(open []string) string {
+	var sb strings.Builder
+	for _, tag := range slices.Backward(open) {
+		sb.WriteString("</" + tagName(tag) + ">")
+	}
+	return sb.String()
+}
+
+// cut returns the longest prefix of s of at most n characters.
+func cut(s string, n int) string {
+	size := 0
+	for i, r := range s {
+		size += utf16.RuneLen(r)
+		if size > n {
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+)
+
+// checkParts checks that every part fits the limit and is balanced HTML, with
+// no entity cut in two.
+func checkParts(t *testing.T, parts []string) {
+	t.Helper()
+	for i, part := range parts {
+		if n := Length(part); n > MaxMessageLength {
+			t.Errorf("part %d: length %d exceeds %d", i, n, MaxMessageLength)
+		}
+		if strings.Count(part, "&") != strings.Count(part, "&amp;")+strings.Count(part, "&lt;")+strings.Count(part, "&gt;") {
+			t.Errorf("part %d: cut inside an entity", i)
+		}
+		for _, tag := range []string{"b", "code", "pre"} {
+			if open, closed := strings.Count(part, "<"+tag+">"), strings.Count(part, "</"+tag+">"); open != closed {
+				t.Errorf("part %d: %d <%s> but %d </%s>", i, open, tag, closed, tag)
+			}
+		}
+	}
+}
+
+func TestSplitShortText(t *testing.T) {
+	text := "<b>API</b> is down"
+	if got := Split(text); len(got) != 1 || got[0] != text {
+		t.Errorf("Split(%q) = %q", text, got)
+	}
+}
+
+func TestSplitAtLineBreaks(t *testing.T) {
+	line := strings.Repeat("x", 99)
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = line
+	}
+	text := strings.Join(lines, "\n")
+
+	parts := Split(text)
+	if len(parts) != 3 {
+		t.Fatalf("got %d parts, want 3", len(parts))
+	}
+	checkParts(t, parts)
+	if got := strings.Join(parts, "\n"); got != text {
+		t.Error("parts do not add up to the text")
+	}
+}
+
+func TestSplitNeverCutsEntities(t *testing.T) {
+	// 4095 characters of text, then entities straddling the limit
+	text := strings.Repeat("x", MaxMessageLength-1) + strings.Repeat(Escape("&"), 10)
+
+	parts := Split(text)
+	checkParts(t, parts)
+	if got := strings.Join(parts, ""); got != text {
+		t.Error("parts do not add up to the text")
+	}
+}
+
+func TestSplitReopensTags(t *testing.T) {
+	body := strings.Repeat(Escape("a<b>&c "), 1000)
+	text := "<b>API</b> is down\n<pre><code>" + body + "</code></pre>\nend"
+
+	parts := Split(text)
+	if len(parts) < 3 {
+		t.Fatalf("got %d part(s), want the code block split", len(parts))
+	}
+	checkParts(t, parts)
+	if parts[0] != "<b>API</b> is down" {
+		t.Errorf("part 0 = %q, want the first line", parts[0])
+	}
+	code, last := parts[1:len(parts)-1], parts[len(parts)-1]
+	for i, part := range code {
+		if !strings.HasPrefix(part, "<pre><code>") || !strings.HasSuffix(part, "</code></pre>") {
+			t.Errorf("part %d is not a complete code block", i+1)
+		}
+	}
+	if !strings.HasPrefix(last, "<pre><code>") || !strings.HasSuffix(last, "</code></pre>\nend") {
+		t.Errorf("last part does not reopen the code block and end the text")
+	}
+}
+
+func TestSplitPlainKeepsAngleBrackets(t *testing.T) {
+	text := "<nil>" + strings.Repeat("x", MaxMessageLength)
+
+	parts := SplitPlain(text)
+	if got := strings.Join(parts, ""); got != text {
+		t.Errorf("plain text changed by splitting: %d parts", len(parts))
+	}
+}
+
+func TestLengthCountsUTF16(t *testing.T) {
+	if got := Length("🔴 down"); got != 7 {
+		t.Errorf("Length = %d, want 7", got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := Truncate("connection refused", 10); got != "connectio…" {
+		t.Errorf("Truncate = %q", got)
+	}
+	if got := Truncate("short", 10); got != "short" {
+		t.Errorf("Truncate = %q", got)
+	}
+}